@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+
+	"maschine_chords_converter/internal/converter"
+)
+
+// runDiff loads two chord set JSON files and prints the differences between them.
+func runDiff(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("diff requires two chord set files: -diff old.json new.json")
+	}
+
+	oldSet, err := converter.LoadChordSet(args[0])
+	if err != nil {
+		return err
+	}
+
+	newSet, err := converter.LoadChordSet(args[1])
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(converter.DiffChordSets(oldSet, newSet))
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"maschine_chords_converter/internal/converter"
+)
+
+// exampleSetName is the name of the example set folder created by runInit.
+const exampleSetName = "Example"
+
+// exampleChords are the chords of the example set: the triads of C major followed by triads borrowed
+// from C minor and a suspended chord. The notes are relative to the base note.
+var exampleChords = []converter.Chord{
+	{Name: "Cmaj", Notes: []int{0, 4, 7}},
+	{Name: "Dmin", Notes: []int{2, 5, 9}},
+	{Name: "Emin", Notes: []int{4, 7, 11}},
+	{Name: "Fmaj", Notes: []int{5, 9, 12}},
+	{Name: "Gmaj", Notes: []int{7, 11, 14}},
+	{Name: "Amin", Notes: []int{9, 12, 16}},
+	{Name: "Bdim", Notes: []int{11, 14, 17}},
+	{Name: "Cmin", Notes: []int{0, 3, 7}},
+	{Name: "Ebmaj", Notes: []int{3, 7, 10}},
+	{Name: "Abmaj", Notes: []int{8, 12, 15}},
+	{Name: "Bbmaj", Notes: []int{10, 14, 17}},
+	{Name: "Csus4", Notes: []int{0, 5, 7}},
+}
+
+// runInit creates the folder with an example set folder containing a correctly named MIDI file for each chord.
+// It returns an error if the example set folder already exists, so that existing files are never overwritten.
+func runInit(folder string, baseNote int) error {
+	setFolder := filepath.Join(folder, exampleSetName)
+	if _, err := os.Stat(setFolder); err == nil {
+		return fmt.Errorf("set folder %s already exists", setFolder)
+	}
+
+	set := converter.ChordSet{Name: exampleSetName, Chords: exampleChords}
+	if err := converter.ExportChordSetToMIDI(set, setFolder, baseNote); err != nil {
+		return err
+	}
+
+	fmt.Printf("created example set folder %s with %d chords\n", setFolder, len(exampleChords))
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"maschine_chords_converter/internal/converter"
+)
+
+func TestRunInit(t *testing.T) {
+	folder := filepath.Join(t.TempDir(), "sets")
+	if err := runInit(folder, 60); err != nil {
+		t.Fatalf("runInit() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(folder, exampleSetName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, e := range entries {
+		files = append(files, e.Name())
+	}
+	var want []string
+	for i, chord := range exampleChords {
+		want = append(want, fmt.Sprintf("%d %s.mid", i+1, chord.Name))
+	}
+	slices.Sort(want)
+	if !slices.Equal(files, want) {
+		t.Errorf("files = %q, want %q", files, want)
+	}
+
+	var sets []converter.ChordSet
+	c := converter.New(converter.WithLogger(slog.New(slog.DiscardHandler)))
+	c.SetSetsFolder(folder)
+	if err := c.RunStream(func(set converter.ChordSet) error {
+		sets = append(sets, set)
+		return nil
+	}); err != nil {
+		t.Fatalf("RunStream() error = %v", err)
+	}
+	if len(sets) != 1 || sets[0].Name != exampleSetName {
+		t.Fatalf("sets = %+v, want the example set", sets)
+	}
+	for i, chord := range sets[0].Chords {
+		if chord.Name != exampleChords[i].Name || !slices.Equal(chord.Notes, exampleChords[i].Notes) {
+			t.Errorf("chord %d = %s %v, want %s %v", i+1, chord.Name, chord.Notes, exampleChords[i].Name, exampleChords[i].Notes)
+		}
+	}
+	if summary := c.LastSummary(); summary.ChordsEmpty != 0 || summary.FilesSkipped != 0 || summary.FilesWithErrors != 0 {
+		t.Errorf("summary = %s, want all chords populated", summary)
+	}
+
+	if err := runInit(folder, 60); err == nil {
+		t.Error("runInit() into an existing set folder: error = nil, want an error")
+	}
+}
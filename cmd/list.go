@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+
+	"maschine_chords_converter/internal/converter"
+)
+
+// runList prints the set folders that would be processed with the number of MIDI files in each.
+// If the input is a zip archive, its set folders are listed.
+func runList(c *converter.Converter, input string) error {
+	list := c.ListSets
+	if isZip(input) {
+		list = func() ([]converter.SetInfo, error) { return c.ListSetsZip(input) }
+	}
+
+	sets, err := list()
+	if err != nil {
+		return err
+	}
+
+	for i, set := range sets {
+		fmt.Printf("%2d  %-10s  %3d MIDI files  %s\n", i+1, set.Name, set.Files, set.Path)
+	}
+	fmt.Printf("%d sets found\n", len(sets))
+
+	return nil
+}
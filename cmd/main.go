@@ -1,21 +1,324 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
 
 	"maschine_chords_converter/internal/converter"
+	"maschine_chords_converter/internal/logger"
 )
 
 const debug = false // if true, the local folder "./sets" is used, for development purposes
 
 func main() {
+	input := flag.String("input", "", "path to the folder or zip archive with chord sets, or comma-separated folders to combine (defaults to the utility folder)")
+	output := flag.String("output", "", "path to the folder for generated JSON files (defaults to the sets folder)")
+	baseNote := flag.Int("base-note", 60, "MIDI note relative to which the note values are calculated (60 = C3)")
+	maxChords := flag.Int("max-chords", 12, "maximum chord number, i.e. the number of chords in a set")
+	numberBase := flag.Int("number-base", 1, "chord number of the first chord in file names, e.g. 0 for files numbered 0..11")
+	numbering := flag.String("numbering", "filename", "chord numbers of files: filename (from the file name) or auto (files without a number fill free slots)")
+	maxSets := flag.Int("max-sets", 16, "maximum number of chord sets to process")
+	strictSets := flag.Bool("strict-sets", false, "fail if more set folders than -max-sets are found instead of ignoring them")
+	tick := flag.Int("tick", -1, "read only notes held at this tick of a MIDI file (negative reads all notes)")
+	dryRun := flag.Bool("dry-run", false, "process sets and report files that would be written without writing them")
+	noteMin := flag.Int("note-min", -60, "lowest allowed relative note value")
+	noteMax := flag.Int("note-max", 67, "highest allowed relative note value")
+	rangePolicy := flag.String("note-range-policy", "ignore", "handling of out of range notes: ignore, clamp, fold, skip or error")
+	schemaVersion := flag.String("schema-version", "1.0.0", "chord set JSON format version")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of set folders processed concurrently")
+	order := flag.String("order", "name-asc", "order of set folders: name-asc, name-desc or mod-time")
+	conflict := flag.String("conflict", "first", "set folders present in several -input folders: first, merge or error")
+	nameSource := flag.String("name-source", "filename", "source of chord names: filename, meta or filename-then-meta")
+	noteMode := flag.String("note-mode", "relative", "note values: relative (to the base note) or absolute (MIDI note numbers)")
+	continueOnError := flag.Bool("continue-on-error", false, "skip files that fail to parse or read instead of aborting")
+	dedupe := flag.Bool("dedupe", false, "write chord sets with identical chords only once")
+	outputTemplate := flag.String("output-template", "user_chord_set_{index}.json", "output file name template, {index} and {name} are replaced")
+	maxDepth := flag.Int("max-depth", 1, "depth below the sets folder at which set folders are searched (0 means unlimited)")
+	ignore := flag.String("ignore", "", "comma-separated patterns of folder names to skip, e.g. \"Temp*,Backup\"")
+	channel := flag.Int("channel", 0, "read notes only from this MIDI channel (1-16), 0 reads all channels")
+	keepVelocity := flag.Bool("keep-velocity", false, "include notes with velocities in the chord sets")
+	emptyName := flag.String("empty-name", "Chd", "placeholder name for empty chords, {index} is replaced with the chord number")
+	omitEmpty := flag.Bool("omit-empty", false, "leave chords without notes out of the chord sets")
+	transpose := flag.Int("transpose", 0, "interval in semitones by which all notes are shifted")
+	normalize := flag.Bool("normalize", false, "normalize chords to root position within one octave")
+	autoName := flag.Bool("auto-name", false, "name chords by their notes")
+	octaveSpan := flag.Bool("octave-span", false, "suffix detected chord names of voicings wider than an octave with the span, e.g. \"Cmaj(+1oct)\"")
+	flats := flag.Bool("flats", false, "spell note names with flats (Db) instead of sharps (C#)")
+	middleC := flag.Int("middle-c", 3, "octave number of middle C (MIDI note 60) in note names: 3 or 4")
+	watch := flag.Bool("watch", false, "keep running and regenerate the chord sets whenever MIDI files change")
+	stdout := flag.Bool("stdout", false, "write all chord sets to stdout (as a JSON array or CSV) instead of files")
+	manifest := flag.Bool("manifest", false, "also write an index.json file listing the generated files with their set names, chord counts and UUIDs")
+	combined := flag.String("combined", "", "path of a file to which all chord sets are also written as a JSON array")
+	remapFile := flag.String("remap", "", "path of a JSON file mapping source note values to target values, e.g. {\"0\": 12}")
+	strictRemap := flag.Bool("remap-strict", false, "warn about notes not found in the -remap table")
+	lenient := flag.Bool("lenient", false, "use the notes read from corrupt MIDI files before the error instead of failing")
+	timestamp := flag.Bool("timestamp", false, "include the time of the conversion in the generatedAt field of the chord sets")
+	compact := flag.Bool("compact", false, "write minified JSON without indentation")
+	stableUUID := flag.Bool("stable-uuid", false, "derive set UUIDs from the set content instead of generating random ones")
+	include := flag.String("include", "", "comma-separated patterns of set folder names to process, e.g. \"Piano*\"")
+	exclude := flag.String("exclude", "", "comma-separated patterns of set folder names not to process")
+	maxNameLen := flag.Int("max-name-len", 10, "maximum length of set folder names, 0 means unlimited")
+	skipEmptySets := flag.Bool("skip-empty-sets", false, "don't write sets without chords")
+	contiguous := flag.Bool("require-all", false, "require a file for every chord number of each set")
+	format := flag.String("format", "json", "output format: json (a file per set) or csv (a single file)")
+	typeID := flag.String("type-id", "", "value of the typeId field (defaults to the schema type id)")
+	maxNotes := flag.Int("max-notes", 0, "maximum number of notes in a chord, 0 means unlimited")
+	maxNotesPolicy := flag.String("max-notes-policy", "truncate", "handling of chords with too many notes: truncate, warn or error")
+	clean := flag.Bool("clean", false, "remove output files left from previous runs")
+	separators := flag.String("separators", "", "characters allowed between chord number and name besides spaces: _ and -, e.g. \"_-\"")
+	comments := flag.Bool("comments", false, "treat a trailing parenthetical in file names as a comment, e.g. \"3 Cmaj7 (bright).mid\"")
+	trackSource := flag.Bool("track-source", false, "record the MIDI file of each chord in the sourceFile field")
+	noteSort := flag.String("note-order", "ascending", "order of notes in chords: ascending, descending or as-played")
+	list := flag.Bool("list", false, "print the set folders that would be processed with the number of MIDI files in each and exit")
+	verify := flag.Bool("verify", false, "check that each set survives a round trip through MIDI files unchanged")
+	setsName := flag.String("sets-name", "sets", "name of the folder with chord sets next to the utility")
+	fileMode := flag.String("file-mode", "0644", "permissions of the output files as an octal number, e.g. 0664")
+	noSubfolders := flag.Bool("no-subfolders", false, "read only MIDI files directly in set folders, not in their subfolders")
+	color := flag.String("color", "auto", "colored output: auto (if the output is a terminal), always or never")
+	verbose := flag.Bool("v", false, "print a message for each chord read")
+	veryVerbose := flag.Bool("vv", false, "like -v, and also print the notes read from each file")
+	report := flag.String("report", "", "path of a JSON file to which the summary and all issues found are written")
+	quiet := flag.Bool("quiet", false, "print only warnings and errors, and exit without waiting for Enter")
+	initSets := flag.Bool("init", false, "create a sets folder (or the -input folder) with an example set and exit")
+	diff := flag.Bool("diff", false, "compare two chord set files given as arguments: -diff old.json new.json")
+	showVersion := flag.Bool("version", false, "print the utility version and exit")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Printf("maschine_chords_converter %s\n", converter.Version())
+		return
+	}
+
+	if *diff {
+		if err := runDiff(flag.Args()); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
+	if *initSets {
+		folder := *input
+		if folder == "" {
+			folder = *setsName
+		}
+		if err := runInit(folder, *baseNote); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
 	c := converter.New()
 	c.SetDebug(debug)
+	// with -stdout, messages go to stderr to keep stdout valid JSON.
+	logOutput := os.Stdout
+	if *stdout {
+		logOutput = os.Stderr
+		c.SetOutputWriter(os.Stdout)
+	}
+	logLevel := slog.LevelInfo
+	switch {
+	case *quiet:
+		logLevel = slog.LevelWarn
+	case *veryVerbose:
+		logLevel = converter.LevelTrace
+	case *verbose:
+		logLevel = slog.LevelDebug
+	}
+	handler := logger.NewHandler(logOutput, logLevel)
+	colored, err := useColor(*color, logOutput)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	handler.SetColor(colored)
+	c.SetLogger(slog.New(handler))
+	c.SetBaseNote(*baseNote)
+	c.SetMaxChords(*maxChords)
+	c.SetMaxSets(*maxSets)
+	c.SetChordNumberBase(*numberBase)
+	c.SetStrictSetCount(*strictSets)
+	c.SetReferenceTick(*tick)
+	c.SetDryRun(*dryRun)
+	c.SetSchemaVersion(*schemaVersion)
+	c.SetWorkers(*workers)
+	c.SetContinueOnError(*continueOnError)
+	c.SetDedupeSets(*dedupe)
+	c.SetOutputTemplate(*outputTemplate)
+	c.SetMaxDepth(*maxDepth)
+	c.SetChannelFilter(*channel)
+	c.SetKeepVelocity(*keepVelocity)
+	c.SetEmptyChordName(*emptyName)
+	c.SetOmitEmptyChords(*omitEmpty)
+	c.SetTranspose(*transpose)
+	c.SetNormalizeVoicing(*normalize)
+	c.SetAutoName(*autoName)
+	c.SetAnnotateOctaveSpan(*octaveSpan)
+	c.SetCombinedFile(*combined)
+	c.SetManifest(*manifest)
+	c.SetDeterministicUUID(*stableUUID)
+	c.SetCompactJSON(*compact)
+	c.SetTimestamp(*timestamp)
+	c.SetLenient(*lenient)
+	if *remapFile != "" {
+		remap, err := converter.LoadNoteRemap(*remapFile)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		c.SetNoteRemap(remap, *strictRemap)
+	}
+	if *ignore != "" {
+		c.SetIgnorePatterns(strings.Split(*ignore, ",")...)
+	}
+	c.SetMaxSetFolderNameLen(*maxNameLen)
+	c.SetSkipEmptySets(*skipEmptySets)
+	c.SetRequireContiguous(*contiguous)
+	c.SetTypeID(*typeID)
+	c.SetCleanOutput(*clean)
+	c.SetFileNameSeparators(*separators)
+	c.SetIncludeSubfolders(!*noSubfolders)
+	c.SetSetsFolderName(*setsName)
+	c.SetSplitComments(*comments)
+	c.SetTrackSource(*trackSource)
+	c.SetSetFilter(splitList(*include), splitList(*exclude))
 
-	if err := c.Run(); err != nil {
+	policy, err := converter.ParseNoteRangePolicy(*rangePolicy)
+	if err != nil {
 		log.Fatal(err.Error())
 	}
+	c.SetNoteRange(*noteMin, *noteMax, policy)
+
+	setOrder, err := converter.ParseSetOrder(*order)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	c.SetSetOrder(setOrder)
+
+	setConflict, err := converter.ParseSetConflict(*conflict)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	c.SetSetConflict(setConflict)
+
+	numberingMode, err := converter.ParseNumberingMode(*numbering)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	c.SetNumberingMode(numberingMode)
+
+	source, err := converter.ParseNameSource(*nameSource)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	c.SetNameSource(source)
+
+	mode, err := converter.ParseNoteMode(*noteMode)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	c.SetNoteMode(mode)
+
+	limitPolicy, err := converter.ParseNoteLimitPolicy(*maxNotesPolicy)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	c.SetMaxNotesPerChord(*maxNotes, limitPolicy)
+
+	if *middleC != 3 && *middleC != 4 {
+		log.Fatalf("invalid middle C octave %d: must be 3 or 4", *middleC)
+	}
+	c.SetNoteNaming(converter.NoteNaming{Flats: *flats, MiddleC4: *middleC == 4})
+
+	sortOrder, err := converter.ParseNoteSort(*noteSort)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	c.SetNoteSort(sortOrder)
+
+	perm, err := strconv.ParseUint(*fileMode, 8, 32)
+	if err != nil || perm > 0777 {
+		log.Fatalf("invalid file mode %q: must be an octal number from 0 to 0777", *fileMode)
+	}
+	c.SetOutputFileMode(os.FileMode(perm))
+
+	outputFormat, err := converter.ParseOutputFormat(*format)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	c.SetOutputFormat(outputFormat)
+
+	inputs := splitList(*input)
+	if len(inputs) > 1 && slices.ContainsFunc(inputs, isZip) {
+		log.Fatal("a zip archive can't be combined with other -input folders")
+	}
+	if *watch && isZip(*input) {
+		log.Fatal("-watch can't be used with a zip archive as -input, since it watches folders")
+	}
+	if len(inputs) > 0 {
+		c.SetSetsFolders(inputs...)
+	}
+	if *output != "" {
+		c.SetOutputFolder(*output)
+	}
+
+	if *list {
+		if err := runList(&c, *input); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
+	if *verify {
+		if err := runVerify(&c, *input); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
+	if *watch {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if err := c.Watch(ctx); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
+	run := c.Run
+	if isZip(*input) {
+		run = func() error { return c.RunZip(*input) }
+	}
+
+	runErr := run()
+	// the report is also written if the run failed, to show the issue that caused the failure.
+	if *report != "" {
+		if err := writeReport(&c, *report); err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+	if runErr != nil {
+		log.Fatal(runErr.Error())
+	}
+
+	if *quiet {
+		return
+	}
+
+	_, _ = fmt.Fprintln(logOutput, c.LastSummary())
+
+	if *stdout {
+		return
+	}
 
 	if debug {
 		fmt.Println("processing complete...")
@@ -25,3 +328,36 @@ func main() {
 	fmt.Println("processing complete. Press Enter to exit...")
 	_, _ = fmt.Scanln()
 }
+
+// splitList splits a comma-separated flag value, returning nil for an empty value.
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
+
+// isZip reports whether the path is a zip archive, judging by its extension.
+func isZip(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".zip")
+}
+
+// useColor reports whether the output to f should be colored according to the color mode (auto, always or never).
+// In auto mode, the output is colored if f is a terminal and the NO_COLOR environment variable is not set.
+func useColor(mode string, f *os.File) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" {
+			return false, nil
+		}
+		info, err := f.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0, nil
+	}
+
+	return false, fmt.Errorf("unknown color mode: %s", mode)
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"maschine_chords_converter/internal/converter"
+)
+
+// runMainEnv is the environment variable that makes the test binary run main instead of the tests.
+const runMainEnv = "MCC_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) != "" {
+		main()
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// runMain runs the utility with the arguments in a subprocess and returns its stdout and stderr.
+func runMain(t *testing.T, args ...string) (string, string) {
+	t.Helper()
+
+	stdout, stderr, err := runMainErr(args...)
+	if err != nil {
+		t.Fatalf("running %v: %v\n%s", args, err, stderr)
+	}
+
+	return stdout, stderr
+}
+
+// runMainErr is like runMain, but returns the error of the subprocess, e.g. its exit status, instead of failing the test.
+func runMainErr(args ...string) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), runMainEnv+"=1")
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+
+	return stdout.String(), stderr.String(), err
+}
+
+// exampleFolder returns a new sets folder with a set of the example chords.
+func exampleFolder(t *testing.T) string {
+	t.Helper()
+
+	folder := t.TempDir()
+	set := converter.ChordSet{Name: exampleSetName, Chords: exampleChords}
+	if err := converter.ExportChordSetToMIDI(set, filepath.Join(folder, exampleSetName), 60); err != nil {
+		t.Fatal(err)
+	}
+
+	return folder
+}
+
+// exampleZip returns the path of a zip archive with the set folders of exampleFolder.
+func exampleZip(t *testing.T) string {
+	t.Helper()
+
+	zipPath := filepath.Join(t.TempDir(), "pack.zip")
+	f, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	if err := zw.AddFS(os.DirFS(exampleFolder(t))); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return zipPath
+}
+
+func TestUseColor(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for _, tt := range []struct {
+		mode string
+		want bool
+	}{
+		{"always", true},
+		{"never", false},
+		{"auto", false}, // not a terminal
+	} {
+		got, err := useColor(tt.mode, f)
+		if err != nil {
+			t.Errorf("useColor(%q) error = %v", tt.mode, err)
+		}
+		if got != tt.want {
+			t.Errorf("useColor(%q) = %v, want %v", tt.mode, got, tt.want)
+		}
+	}
+
+	if _, err := useColor("sometimes", f); err == nil {
+		t.Error("useColor(\"sometimes\") error = nil, want an error")
+	}
+}
+
+func TestQuiet(t *testing.T) {
+	folder := exampleFolder(t)
+
+	stdout, stderr := runMain(t, "-input", folder, "-quiet")
+	if stdout != "" || stderr != "" {
+		t.Errorf("output of a clean run with -quiet:\nstdout: %q\nstderr: %q", stdout, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(folder, "user_chord_set_01.json")); err != nil {
+		t.Errorf("chord set not written: %v", err)
+	}
+
+	stdout, _ = runMain(t, "-input", folder, "-color", "never")
+	if !strings.Contains(stdout, "generated file") || !strings.Contains(stdout, "summary:") {
+		t.Errorf("output of a run without -quiet = %q, want the generated files and the summary", stdout)
+	}
+}
+
+func TestVeryVerbose(t *testing.T) {
+	folder := exampleFolder(t)
+
+	stdout, _ := runMain(t, "-input", folder, "-vv", "-color", "never")
+	for _, want := range []string{
+		fmt.Sprintf("debug: MIDI notes read file=%q notes=\"[60 64 67]\"", filepath.Join(folder, exampleSetName, "1 Cmaj.mid")),
+		`debug: chord read file=`,
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output with -vv doesn't contain %s:\n%s", want, stdout)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	folder := exampleFolder(t)
+
+	stdout, _ := runMain(t, "-input", folder, "-list", "-color", "never")
+	want := fmt.Sprintf(" 1  %-10s  %3d MIDI files  %s\n1 sets found\n", exampleSetName, len(exampleChords), filepath.Join(folder, exampleSetName))
+	if stdout != want {
+		t.Errorf("output of -list = %q, want %q", stdout, want)
+	}
+	if _, err := os.Stat(filepath.Join(folder, "user_chord_set_01.json")); err == nil {
+		t.Error("-list wrote a chord set")
+	}
+}
+
+func TestListZip(t *testing.T) {
+	zipPath := exampleZip(t)
+
+	stdout, _ := runMain(t, "-input", zipPath, "-list", "-color", "never")
+	want := fmt.Sprintf(" 1  %-10s  %3d MIDI files  %s\n1 sets found\n", exampleSetName, len(exampleChords), filepath.Join(zipPath, exampleSetName))
+	if stdout != want {
+		t.Errorf("output of -list = %q, want %q", stdout, want)
+	}
+	if got := dirEntries(t, filepath.Dir(zipPath)); len(got) != 1 {
+		t.Errorf("files next to the archive = %q, want only the archive", got)
+	}
+}
+
+func TestVerifyZip(t *testing.T) {
+	stdout, _ := runMain(t, "-input", exampleZip(t), "-verify", "-color", "never")
+	if want := "PASS " + exampleSetName + "\n"; stdout != want {
+		t.Errorf("output of -verify = %q, want %q", stdout, want)
+	}
+}
+
+func TestWatchZip(t *testing.T) {
+	_, stderr, err := runMainErr("-input", exampleZip(t), "-watch", "-color", "never")
+	if err == nil {
+		t.Fatal("-watch with a zip archive succeeded, want an error")
+	}
+	if want := "-watch can't be used with a zip archive"; !strings.Contains(stderr, want) {
+		t.Errorf("stderr = %q, want it to contain %q", stderr, want)
+	}
+}
+
+// dirEntries returns the names of the entries of the folder.
+func dirEntries(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	return names
+}
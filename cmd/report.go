@@ -0,0 +1,23 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"maschine_chords_converter/internal/converter"
+)
+
+// writeReport writes the report of the last run of the converter to the file at path as JSON.
+func writeReport(c *converter.Converter, path string) error {
+	data, err := json.MarshalIndent(c.LastReport(), "", "    ")
+	if err != nil {
+		return fmt.Errorf("error marshaling report: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing report %s: %w", path, err)
+	}
+
+	return nil
+}
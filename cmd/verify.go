@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"maschine_chords_converter/internal/converter"
+)
+
+// runVerify converts the chord sets, round-trips each of them through MIDI files and prints the result per set.
+// It returns an error if any set fails the check. If the input is a zip archive, its chord sets are verified.
+func runVerify(c *converter.Converter, input string) error {
+	verify := c.Verify
+	if isZip(input) {
+		verify = func() ([]converter.VerifyResult, error) { return c.VerifyZip(input) }
+	}
+
+	results, err := verify()
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failed++
+			fmt.Printf("FAIL %s: %v\n", result.Set.Name, result.Err)
+		case !result.Diff.Empty():
+			failed++
+			fmt.Printf("FAIL %s: %s\n", result.Set.Name, result.Diff)
+		default:
+			fmt.Printf("PASS %s\n", result.Set.Name)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d sets failed verification", failed, len(results))
+	}
+
+	return nil
+}
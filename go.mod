@@ -2,4 +2,9 @@ module maschine_chords_converter
 
 go 1.24
 
-require gitlab.com/gomidi/midi v1.23.7 // indirect
+require (
+	github.com/fsnotify/fsnotify v1.10.1
+	gitlab.com/gomidi/midi v1.23.7
+)
+
+require golang.org/x/sys v0.13.0 // indirect
@@ -0,0 +1,69 @@
+package converter
+
+import (
+	"fmt"
+	"slices"
+
+	"maschine_chords_converter/internal/helpers"
+)
+
+// NoteNaming defines how note names are spelled (see helpers.NoteNaming).
+type NoteNaming = helpers.NoteNaming
+
+// chordQualities maps the intervals of a chord above its lowest note (in semitones, ascending)
+// to the name suffix of the chord quality.
+var chordQualities = map[string]string{
+	"[0 7]":        "5",
+	"[0 4 7]":      "maj",
+	"[0 3 7]":      "min",
+	"[0 3 6]":      "dim",
+	"[0 4 8]":      "aug",
+	"[0 2 7]":      "sus2",
+	"[0 5 7]":      "sus4",
+	"[0 4 7 9]":    "6",
+	"[0 3 7 9]":    "min6",
+	"[0 4 7 11]":   "maj7",
+	"[0 3 7 10]":   "min7",
+	"[0 4 7 10]":   "7",
+	"[0 3 6 9]":    "dim7",
+	"[0 3 6 10]":   "min7b5",
+	"[0 3 7 11]":   "minMaj7",
+	"[0 4 8 11]":   "augMaj7",
+	"[0 5 7 10]":   "7sus4",
+	"[0 2 4 7]":    "add9",
+	"[0 2 4 7 11]": "maj9",
+	"[0 2 3 7 10]": "min9",
+	"[0 2 4 7 10]": "9",
+}
+
+// DetectChordName returns the name of the chord formed by the given MIDI note numbers, e.g. "Cmaj" or "F#min7".
+// The chord quality is recognized from the intervals above the lowest note, which is taken as the root;
+// octave doublings are ignored. An empty string is returned if the chord is not recognized.
+// The root is spelled with sharps; use DetectChordNameWith for other spellings.
+func DetectChordName(notes []int) string {
+	return DetectChordNameWith(notes, NoteNaming{})
+}
+
+// DetectChordNameWith is like DetectChordName, but spells the root according to naming, e.g. "Dbmin7".
+func DetectChordNameWith(notes []int, naming NoteNaming) string {
+	if len(notes) == 0 {
+		return ""
+	}
+
+	root := slices.Min(notes)
+	intervals := make([]int, 0, len(notes))
+	for _, n := range notes {
+		interval := (n - root) % 12
+		if !slices.Contains(intervals, interval) {
+			intervals = append(intervals, interval)
+		}
+	}
+	slices.Sort(intervals)
+
+	quality, ok := chordQualities[fmt.Sprint(intervals)]
+	if !ok {
+		return ""
+	}
+
+	return helpers.PitchClassName(root, naming) + quality
+}
@@ -0,0 +1,113 @@
+package converter
+
+import (
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDetectChordName(t *testing.T) {
+	tests := []struct {
+		notes []int
+		want  string
+	}{
+		{[]int{60, 64, 67}, "Cmaj"},
+		{[]int{62, 65, 69}, "Dmin"},
+		{[]int{71, 74, 77}, "Bdim"},
+		{[]int{60, 64, 68}, "Caug"},
+		{[]int{62, 64, 69}, "Dsus2"},
+		{[]int{62, 67, 69}, "Dsus4"},
+		{[]int{65, 69, 72, 76}, "Fmaj7"},
+		{[]int{57, 60, 64, 67}, "Amin7"},
+		{[]int{67, 71, 74, 77}, "G7"},
+		{[]int{59, 62, 65, 69}, "Bmin7b5"},
+		{[]int{61, 64, 67, 70}, "C#dim7"},
+		{[]int{48, 60, 64, 67, 72}, "Cmaj"}, // octave doublings
+		{[]int{67, 60, 64}, "Cmaj"},         // unsorted
+		{[]int{64, 67, 72}, ""},             // inversion, the lowest note is taken as the root
+		{[]int{60, 61, 62}, ""},             // cluster
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := DetectChordName(tt.notes); got != tt.want {
+			t.Errorf("DetectChordName(%v) = %q, want %q", tt.notes, got, tt.want)
+		}
+	}
+}
+
+func TestDetectChordNameWithFlats(t *testing.T) {
+	if got := DetectChordNameWith([]int{61, 64, 68, 71}, NoteNaming{Flats: true}); got != "Dbmin7" {
+		t.Errorf("DetectChordNameWith() = %q, want %q", got, "Dbmin7")
+	}
+}
+
+func TestAutoName(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/1 My chord.mid": smfFile(smfChord(t, 62, 65, 69)),
+		"Set/2 .mid":         smfFile(smfChord(t, 67, 71, 74, 77)),
+		"Set/3 .mid":         smfFile(smfChord(t, 60, 61, 62)),
+	}
+
+	for _, tt := range []struct {
+		autoName bool
+		want     []string
+	}{
+		{false, []string{"My chord", "G7", "Chd 3"}},
+		{true, []string{"Dmin", "G7", "Chd 3"}},
+	} {
+		c := testConverter()
+		c.SetMaxChords(3)
+		c.SetAutoName(tt.autoName)
+		sets := convertFS(t, &c, fsys)
+
+		if got := chordNames(sets[0].Chords); !slices.Equal(got, tt.want) {
+			t.Errorf("auto name %v: chords = %q, want %q", tt.autoName, got, tt.want)
+		}
+	}
+}
+
+func TestNoteNamingInDetectedNames(t *testing.T) {
+	fsys := fstest.MapFS{"Set/1 .mid": smfFile(smfChord(t, 61, 64, 68))}
+
+	for _, tt := range []struct {
+		naming NoteNaming
+		want   string
+	}{
+		{NoteNaming{}, "C#min"},
+		{NoteNaming{Flats: true}, "Dbmin"},
+	} {
+		c := testConverter()
+		c.SetNoteNaming(tt.naming)
+		sets := convertFS(t, &c, fsys)
+
+		if got := sets[0].Chords[0].Name; got != tt.want {
+			t.Errorf("naming %+v: chord 1 = %q, want %q", tt.naming, got, tt.want)
+		}
+	}
+}
+
+func TestAnnotateOctaveSpan(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/1 .mid":      smfFile(smfChord(t, 60, 64, 67)),
+		"Set/2 .mid":      smfFile(smfChord(t, 48, 64, 67)),
+		"Set/3 .mid":      smfFile(smfChord(t, 36, 64, 79)),
+		"Set/4 Named.mid": smfFile(smfChord(t, 36, 64, 79)),
+	}
+
+	for _, tt := range []struct {
+		annotate bool
+		want     []string
+	}{
+		{false, []string{"Cmaj", "Cmaj", "Cmaj", "Named"}},
+		{true, []string{"Cmaj", "Cmaj(+1oct)", "Cmaj(+3oct)", "Named"}},
+	} {
+		c := testConverter()
+		c.SetMaxChords(4)
+		c.SetAnnotateOctaveSpan(tt.annotate)
+		sets := convertFS(t, &c, fsys)
+
+		if got := chordNames(sets[0].Chords); !slices.Equal(got, tt.want) {
+			t.Errorf("annotate %v: chords = %q, want %q", tt.annotate, got, tt.want)
+		}
+	}
+}
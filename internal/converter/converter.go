@@ -1,266 +1,2155 @@
 package converter
 
 import (
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
+	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
-	"gitlab.com/gomidi/midi/reader"
+	"maschine_chords_converter/internal/helpers"
+)
+
+const (
+	version             = "1.0.0" // defines the current (default) schema version of the chord sets
+	baseChordName       = "Chd"   // is used for creating a default chord name (for empty chords)
+	defaultBaseNote     = 60      // the default base note (C3) relative to which the note values will be calculated
+	minMidiNote         = 0       // the lowest MIDI note number
+	maxMidiNote         = 127     // the highest MIDI note number
+	defaultMinRelNote   = -60     // the default lowest allowed relative note value
+	defaultMaxRelNote   = 67      // the default highest allowed relative note value
+	maxSetFolderNameLen = 10      // defines the default maximum length for a chord set folder name
+	minChordNumber      = 1       // the minimum allowed chord number (and the default number of the first chord)
+	maxFileChordNumber  = 999     // the highest chord number that fits in a file name (3 digits)
+	defaultMaxChords    = 12      // the default maximum chord number (and, consequently, the number of chords in a set)
+	defaultMaxSets      = 16      // the default maximum number of chord sets that can be processed
+	defaultMaxDepth     = 1       // the default depth below the sets folder at which set folders are searched
+	allChannels         = 0       // channel filter value for reading notes on all MIDI channels
+	maxMidiChannel      = 16      // the highest MIDI channel number
+	setsFolderName      = "sets"  // default folder name for chord sets
+
+	defaultOutputTemplate = "user_chord_set_{index}.json" // default output file name template
+	csvFileName           = "chord_sets.csv"              // name of the file written in the CSV output format
+	defaultFileMode       = os.FileMode(0644)             // default permissions of the output files
+	nameSeparators        = "_-"                          // characters allowed as separators between chord number and name
+)
+
+// ErrNoNotes is returned when a MIDI file contains no notes.
+var ErrNoNotes = errors.New("no notes found")
+
+// ErrMissingChords is returned when a set doesn't have a chord for every number and contiguous numbering is required.
+var ErrMissingChords = errors.New("missing chord numbers")
+
+// ErrDuplicateNumber is returned when several files of a set have the same chord number.
+var ErrDuplicateNumber = errors.New("duplicate chord number")
+
+// ErrTooManySets is returned when more set folders than the maximum number of sets are found in strict mode.
+var ErrTooManySets = errors.New("too many set folders")
+
+// ErrSkipSet can be returned by a TransformFunc to leave the set out of the output without failing the run.
+var ErrSkipSet = errors.New("skip set")
+
+// LevelTrace is the log level of detailed messages about the notes read from each file,
+// below slog.LevelDebug which is used for messages about each chord and skipped folders.
+const LevelTrace = slog.LevelDebug - 4
+
+// re regular expression used to validate and parse MIDI file names without extension.
+// Expected file name format: "12 Amin9.mid", "1 Cmin.midi" or "100 Cmaj.mid" (for sets with more than 99 chords).
+// The number and the name may be separated by several spaces or tabs, e.g. "3  Cmaj.mid".
+var re = regexp.MustCompile(`^(\d{1,3})\s+(.*)$`)
+
+// commentRe matches a chord name with a trailing parenthetical comment, e.g. "Cmaj7 (bright)".
+var commentRe = regexp.MustCompile(`^(.*?)\s*\(([^()]*)\)$`)
+
+// uuidNamespace is the namespace for deterministic chord set UUIDs.
+var uuidNamespace = [16]byte{0x3c, 0x5e, 0x8a, 0x41, 0x97, 0x0d, 0x4f, 0x62, 0xb1, 0x2e, 0x6d, 0x84, 0x1a, 0xc9, 0x53, 0xf7}
+
+// defaultMidiExtensions defines the default allowed extensions for MIDI files
+var defaultMidiExtensions = []string{".mid", ".midi"}
+
+// Chord represents a single chord.
+type Chord struct {
+	Name              string    `json:"name"`                        // name of a chord
+	Notes             []int     `json:"notes"`                       // slice of chord notes
+	NotesWithVelocity []NoteVel `json:"notesWithVelocity,omitempty"` // slice of chord notes with velocities (optional)
+	Comment           string    `json:"comment,omitempty"`           // comment from the file name (optional)
+	SourceFile        string    `json:"sourceFile,omitempty"`        // path of the MIDI file within the sets folder (optional)
+
+	slot int // chord slot in the set (starting from 1) the chord was read into, 0 if unknown (e.g. for loaded sets)
+}
+
+// NoteVel represents a chord note along with its velocity.
+type NoteVel struct {
+	Note     int `json:"note"`     // note value
+	Velocity int `json:"velocity"` // velocity of the note (1-127)
+}
+
+// ChordSet represents a set of chords along with properties required for generating a JSON file.
+type ChordSet struct {
+	Chords      []Chord `json:"chords"`                // slice of chords
+	Name        string  `json:"name"`                  // name of a set
+	TypeID      string  `json:"typeId"`                // metadata
+	UUID        string  `json:"uuid"`                  // metadata
+	Version     string  `json:"version"`               // metadata
+	GeneratedAt string  `json:"generatedAt,omitempty"` // time of the conversion in RFC 3339 format (optional)
+
+	numberOffset int // difference between the chord numbers in file names and the chord slots (chord number base - 1)
+}
+
+// Converter converts MIDI files into JSON chord sets.
+type Converter struct {
+	chordSets       []ChordSet      // processed chord sets
+	summary         Summary         // statistics of the last run
+	timings         Timings         // durations of the last run
+	setsFolder      string          // path to the folder containing chord set directories
+	fsys            fs.FS           // file system from which the chord sets of the current run are read
+	fsRoot          string          // folder containing chord set directories within fsys
+	osRoot          string          // OS path of fsRoot, used to report paths (empty if fsys is not an OS folder)
+	outDir          string          // default folder for output files of the current run
+	outputFolder    string          // path to the folder for generated JSON files (optional)
+	baseNote        int             // the note relative to which the note values are calculated
+	maxChords       int             // the maximum allowed chord number (and the number of chords in a set)
+	maxSets         int             // the maximum number of chord sets that can be processed
+	refTick         int             // if not negative, only notes sounding at this tick are read
+	extensions      []string        // allowed MIDI file extensions (case-insensitive)
+	minRelNote      int             // the lowest allowed relative note value
+	maxRelNote      int             // the highest allowed relative note value
+	rangePolicy     NoteRangePolicy // defines how notes outside the allowed relative range are handled
+	schemaVer       string          // chord set JSON format version
+	workers         int             // number of set folders processed concurrently
+	setOrder        SetOrder        // order in which set folders are numbered
+	nameSource      NameSource      // where chord names are taken from
+	noteMode        NoteMode        // whether notes are written relative to the base note or as MIDI note numbers
+	logger          *slog.Logger    // logger for processing messages
+	fileErrs        []error         // errors of files skipped in continue-on-error mode during the last run
+	issues          []Issue         // issues found during the last run, see LastReport
+	continueOnError bool            // if true, files that fail are skipped instead of aborting the set
+	dedupeSets      bool            // if true, chord sets with identical chords are written only once
+	outputTemplate  string          // output file name template with {index} and {name} placeholders
+	maxDepth        int             // how deep below the setsFolder set folders are searched (0 means unlimited)
+	ignorePatterns  []string        // filepath.Match patterns of folder names to skip
+	includeSets     []string        // filepath.Match patterns of set folder names to process (all if empty)
+	maxNameLen      int             // maximum length of a set folder name in characters, 0 means unlimited
+	skipEmptySets   bool            // if true, sets without chords are not written
+	outputFormat    OutputFormat    // format of the generated files
+	typeID          string          // value of the typeId field, if empty the schema default is used
+	reader          MidiReader      // reader of the chord notes, if nil MIDI files are read with gomidi
+	maxNotes        int             // maximum number of notes in a chord, 0 means unlimited
+	progress        ProgressFunc    // if set, called after each set folder is processed
+	cleanOutput     bool            // if true, stale output files from previous runs are removed
+	splitComments   bool            // if true, trailing parenthetical comments are split from chord names
+	noteSort        NoteSort        // order of notes in the chords
+	setsName        string          // name of the folder containing the chord set folders next to the utility
+	fileMode        os.FileMode     // permissions of the output files
+	flatSets        bool            // if true, only files directly in a set folder are read, not in its subfolders
+	noteLimitPolicy NoteLimitPolicy // how chords with more notes than maxNotes are handled
+	excludeSets     []string        // filepath.Match patterns of set folder names not to process
+	channel         int             // MIDI channel (1-16) notes are read from, allChannels for all channels
+	keepVelocity    bool            // if true, chords also include notes with velocities
+	emptyChordName  string          // placeholder name for empty chords, optionally with the {index} placeholder
+	omitEmptyChords bool            // if true, chords without notes are not written
+	transpose       int             // interval in semitones added to all notes
+	normalize       bool            // if true, chords are normalized to root position within one octave
+	autoName        bool            // if true, chord names are detected from the notes
+	outputWriter    io.Writer       // if set, all chord sets are written to it as a JSON array instead of files
+	combinedFile    string          // if set, all chord sets are also written to this file as a JSON array
+	stableUUID      bool            // if true, set UUIDs are derived from the set content instead of being random
+	contiguous      bool            // if true, every chord number from 1 to maxChords must have a file
+	noteNaming      NoteNaming      // spelling of note names in detected chord names and messages
+	separators      string          // characters separating the chord number from the name in addition to whitespace
+	nameRe          *regexp.Regexp  // file name regular expression of the current run, built from the separators
+	outPattern      *regexp.Regexp  // output file name pattern of the current run, see outputFilePattern
+	strictSets      bool            // if true, finding more set folders than maxSets is an error
+	trackSource     bool            // if true, chords include the path of their MIDI file
+	transform       TransformFunc   // if set, applied to each chord set before output
+	octaveSpan      bool            // if true, detected chord names are suffixed with the octave span of wide voicings
+	compactJSON     bool            // if true, JSON is written without indentation and line breaks
+	timestamp       bool            // if true, chord sets include the time of the conversion
+	lenient         bool            // if true, notes read from corrupt MIDI files before the error are used
+	remap           map[int]int     // note values replaced after reading (source to target), see LoadNoteRemap
+	strictRemap     bool            // if true, notes missing from the remap table are reported
+	numberBase      int             // chord number of the first chord in file names, e.g. 0 for files numbered 0..11
+	now             Clock           // clock for timestamps and timings, time.Now unless replaced with SetClock
+	numbering       NumberingMode   // how chord numbers are assigned to files
+	manifest        bool            // if true, an index.json file listing the generated files is written
+	moreInputs      []string        // input folders whose set folders are combined with those of the setsFolder
+	setConflict     SetConflict     // how set folders present in several input folders are handled
+	dryRun          bool            // if true, JSON files are not written
+	debug           bool            // debug mode flag
+}
+
+// Version returns the version of the utility, which is also the default schema version of the chord sets.
+func Version() string {
+	return version
+}
+
+// New creates and returns a new Converter instance with the default configuration changed by the given options,
+// e.g. New(WithBaseNote(48), WithOutputFolder("out")). The configuration can also be changed later with setters.
+func New(opts ...Option) Converter {
+	c := Converter{
+		chordSets:      make([]ChordSet, 0, defaultMaxSets),
+		baseNote:       defaultBaseNote,
+		maxChords:      defaultMaxChords,
+		numberBase:     minChordNumber,
+		maxSets:        defaultMaxSets,
+		refTick:        -1,
+		extensions:     defaultMidiExtensions,
+		minRelNote:     defaultMinRelNote,
+		maxRelNote:     defaultMaxRelNote,
+		schemaVer:      version,
+		workers:        runtime.NumCPU(),
+		logger:         slog.New(slog.DiscardHandler),
+		outputTemplate: defaultOutputTemplate,
+		maxDepth:       defaultMaxDepth,
+		emptyChordName: baseChordName,
+		maxNameLen:     maxSetFolderNameLen,
+		setsName:       setsFolderName,
+		fileMode:       defaultFileMode,
+		now:            time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c
+}
+
+// SetDebug sets the debug mode of the Converter instance.
+func (c *Converter) SetDebug(debug bool) {
+	c.debug = debug
+}
+
+// SetSetsFolder sets the path to the folder containing chord set directories.
+// When set, it takes precedence over both the executable directory and the debug folder.
+func (c *Converter) SetSetsFolder(path string) {
+	c.setsFolder = path
+	c.moreInputs = nil
+}
+
+// SetNumberingMode sets how chord numbers are assigned to files. With NumberingAutoSequential, files without
+// a chord number in their names (e.g. "Cmaj.mid") fill the free chord slots in the order of their paths,
+// after the files with numbers, and the whole file name is the chord name.
+func (c *Converter) SetNumberingMode(mode NumberingMode) {
+	c.numbering = mode
+}
+
+// SetManifest sets whether an index.json file listing the generated chord set files with their set names,
+// numbers of chords and UUIDs is written to the output folder, e.g. as an entry point for other tools.
+// It is only written in the JSON output format.
+func (c *Converter) SetManifest(manifest bool) {
+	c.manifest = manifest
+}
+
+// SetSetsFolders sets several folders containing chord set directories, whose set folders are combined into
+// one library (subject to the maximum number of sets). The first folder is used like the folder set with
+// SetSetsFolder, e.g. output files are written to it. Set folders with the same path in several folders
+// are handled according to SetSetConflict.
+func (c *Converter) SetSetsFolders(paths ...string) {
+	c.setsFolder = ""
+	c.moreInputs = nil
+	if len(paths) > 0 {
+		c.setsFolder = paths[0]
+		c.moreInputs = paths[1:]
+	}
+}
+
+// SetSetConflict sets how set folders with the same path in several input folders are handled
+// (SetConflictFirst by default). It only matters when several folders are set with SetSetsFolders.
+func (c *Converter) SetSetConflict(policy SetConflict) {
+	c.setConflict = policy
+}
+
+// SetOutputFolder sets the path to the folder where JSON files are written.
+// If empty, JSON files are written next to the sets folder.
+func (c *Converter) SetOutputFolder(path string) {
+	c.outputFolder = path
+}
+
+// SetBaseNote sets the MIDI note relative to which the note values are calculated.
+// The value must be in the MIDI range 0–127, otherwise Run returns an error.
+func (c *Converter) SetBaseNote(n int) {
+	c.baseNote = n
+}
+
+// SetMaxChords sets the maximum allowed chord number, which is also the number of chords in a set.
+// The value must be at least 1, otherwise Run returns an error.
+func (c *Converter) SetMaxChords(n int) {
+	c.maxChords = n
+}
+
+// SetChordNumberBase sets the chord number of the first chord in file names (1 by default), e.g. 0 for files
+// numbered from 0 to 11. Chord numbers from base to base+maxChords-1 are accepted.
+func (c *Converter) SetChordNumberBase(base int) {
+	c.numberBase = base
+}
+
+// SetMaxSets sets the maximum number of chord sets that can be processed.
+// Set folders beyond this limit are ignored with a warning. The value must be at least 1.
+func (c *Converter) SetMaxSets(n int) {
+	c.maxSets = n
+}
+
+// SetStrictSetCount sets whether finding more set folders than the maximum number of sets is an error
+// (wrapping ErrTooManySets) that lists the folders beyond the limit, instead of ignoring them with a warning.
+func (c *Converter) SetStrictSetCount(strict bool) {
+	c.strictSets = strict
+}
+
+// SetReferenceTick sets the tick (position from the start of a MIDI file) at which the chord notes are captured.
+// Only notes that are held at this tick are read. A negative value (default) reads all notes of a file.
+func (c *Converter) SetReferenceTick(tick int) {
+	c.refTick = tick
+}
+
+// SetMidiExtensions sets the allowed MIDI file extensions, e.g. ".mid". Extensions are compared case-insensitively.
+func (c *Converter) SetMidiExtensions(extensions ...string) {
+	c.extensions = extensions
+}
+
+// SetNoteRange sets the allowed range of relative note values and the policy applied to notes outside it.
+// With the default NoteRangeIgnore policy, the range is not checked.
+func (c *Converter) SetNoteRange(minNote, maxNote int, policy NoteRangePolicy) {
+	c.minRelNote = minNote
+	c.maxRelNote = maxNote
+	c.rangePolicy = policy
+}
+
+// SetSchemaVersion sets the chord set JSON format version, which selects the type id and the field layout of
+// the chord set files. Run returns an error for unsupported versions.
+func (c *Converter) SetSchemaVersion(version string) {
+	c.schemaVer = version
+}
+
+// SetWorkers sets the number of set folders processed concurrently. The value must be at least 1.
+func (c *Converter) SetWorkers(n int) {
+	c.workers = n
+}
+
+// SetSetOrder sets the order in which set folders are processed and numbered in output file names.
+func (c *Converter) SetSetOrder(order SetOrder) {
+	c.setOrder = order
+}
+
+// SetNameSource sets where chord names are taken from. The chord number is always taken from the file name.
+func (c *Converter) SetNameSource(source NameSource) {
+	c.nameSource = source
+}
+
+// SetNoteMode sets whether notes are written relative to the base note (default) or as absolute MIDI note numbers.
+// The note range set by SetNoteRange applies to the written values.
+func (c *Converter) SetNoteMode(mode NoteMode) {
+	c.noteMode = mode
+}
+
+// SetLogger sets the logger for processing messages. By default, messages are discarded.
+// A nil logger discards messages.
+func (c *Converter) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	c.logger = logger
+}
+
+// SetContinueOnError sets the continue-on-error mode. In this mode, a file that fails to parse or read is logged
+// and skipped, and the set is produced from the remaining files. Run then returns an aggregated error listing all
+// skipped files after the output is written. By default, the first failing file aborts the run.
+func (c *Converter) SetContinueOnError(continueOnError bool) {
+	c.continueOnError = continueOnError
+}
+
+// SetDedupeSets sets whether chord sets with identical chords (ignoring set names and UUIDs) are written only once.
+// The first set in order is kept, the duplicates are skipped with a message.
+func (c *Converter) SetDedupeSets(dedupe bool) {
+	c.dedupeSets = dedupe
+}
+
+// SetOutputTemplate sets the output file name template. The {index} placeholder is replaced
+// with the two-digit set number, and {name} with the set name, e.g. "{name}.json". The template must contain
+// at least one of the placeholders, and the run fails if two sets get the same file name.
+func (c *Converter) SetOutputTemplate(template string) {
+	c.outputTemplate = template
+}
+
+// SetMaxDepth sets how deep below the sets folder set folders are searched: 1 (default) means only its direct
+// subfolders (or the subfolders of its "sets" folder) are treated as sets. 0 means unlimited depth.
+func (c *Converter) SetMaxDepth(depth int) {
+	c.maxDepth = depth
+}
+
+// SetIgnorePatterns sets filepath.Match patterns of folder names that are skipped during traversal,
+// e.g. "Temp*". Hidden folders (starting with a dot) are always skipped.
+func (c *Converter) SetIgnorePatterns(patterns ...string) {
+	c.ignorePatterns = patterns
+}
+
+// SetMaxSetFolderNameLen sets the maximum length of set folder names in characters (10 by default, as Maschine
+// displays only 10 characters). Folders with longer names are skipped. 0 means unlimited.
+func (c *Converter) SetMaxSetFolderNameLen(length int) {
+	c.maxNameLen = length
+}
+
+// SetSkipEmptySets sets whether sets without chords (e.g. folders without correctly named MIDI files) are skipped.
+// By default, such sets are written with placeholder chords only, and a warning is logged.
+func (c *Converter) SetSkipEmptySets(skip bool) {
+	c.skipEmptySets = skip
+}
+
+// SetRequireContiguous sets whether every set must have a file for each chord number from 1 to the maximum number
+// of chords. A set with gaps is an error that reports the missing numbers; in continue-on-error mode,
+// a warning is logged instead and the gaps are filled with empty chords.
+func (c *Converter) SetRequireContiguous(require bool) {
+	c.contiguous = require
+}
+
+// SetFileNameSeparators sets the characters that may separate the chord number from the chord name in file names
+// in addition to whitespace, e.g. "_-" to accept "03_Cmaj.mid" and "03-Cmaj.mid". Only underscores and hyphens
+// are supported. By default, only whitespace is accepted.
+func (c *Converter) SetFileNameSeparators(separators string) {
+	c.separators = separators
+}
+
+// SetOutputFormat sets the format of the generated files: a JSON file per chord set (default) or a single CSV file
+// with a row per chord, e.g. for analysis in a spreadsheet.
+func (c *Converter) SetOutputFormat(format OutputFormat) {
+	c.outputFormat = format
+}
+
+// SetTypeID sets the value of the typeId field of the chord sets, e.g. to reuse the format for other targets.
+// If empty (default), the type id of the schema version is used. A warning is logged for unknown type ids.
+func (c *Converter) SetTypeID(typeID string) {
+	c.typeID = typeID
+}
+
+// SetMidiReader sets the reader used to read the chord notes from the chord files. If nil (default), Standard MIDI
+// Files are read, respecting the channel filter and the reference tick. A custom reader is responsible for both.
+func (c *Converter) SetMidiReader(r MidiReader) {
+	c.reader = r
+}
+
+// SetMaxNotesPerChord sets the maximum number of notes in a chord (0, the default, means unlimited)
+// and how chords with more notes are handled.
+func (c *Converter) SetMaxNotesPerChord(maxNotes int, policy NoteLimitPolicy) {
+	c.maxNotes = maxNotes
+	c.noteLimitPolicy = policy
+}
+
+// SetProgressFunc sets a function that is called after each set folder is processed, e.g. to show a progress bar.
+// Calls are serialized, but may come from different goroutines. Passing nil disables progress reports.
+func (c *Converter) SetProgressFunc(fn ProgressFunc) {
+	c.progress = fn
+}
+
+// SetTransform sets a function that post-processes each chord set before output, e.g. to apply custom naming
+// or to reorder chords. It is called in the order of the sets, after duplicate sets and empty chords are removed.
+// Passing nil disables the transformation.
+func (c *Converter) SetTransform(fn TransformFunc) {
+	c.transform = fn
+}
+
+// SetCleanOutput sets whether files in the output folder that match the output template, but were not written
+// in the current run (e.g. left from a previous run with more sets), are removed. By default, a warning is logged.
+// It can't be used with the {name} placeholder in the output template, since any file with the same prefix and suffix
+// (e.g. any JSON file for "{name}.json") would match the template.
+func (c *Converter) SetCleanOutput(clean bool) {
+	c.cleanOutput = clean
+}
+
+// SetSplitComments sets whether a trailing parenthetical in the file name is treated as a comment rather than
+// a part of the chord name: "3 Cmaj7 (bright).mid" gives the chord "Cmaj7" with the comment "bright".
+func (c *Converter) SetSplitComments(split bool) {
+	c.splitComments = split
+}
+
+// SetTrackSource sets whether each chord records the path of the MIDI file it was read from (relative to the sets
+// folder, e.g. "Piano/3 Cmaj7.mid") in the SourceFile field, to map chords back to their files.
+func (c *Converter) SetTrackSource(track bool) {
+	c.trackSource = track
+}
+
+// SetNoteSort sets the order of notes in the chords: ascending (default), descending or as played,
+// i.e. by the onset tick of each note (e.g. for rolled chords or arpeggio-based pads).
+func (c *Converter) SetNoteSort(order NoteSort) {
+	c.noteSort = order
+}
+
+// SetSetsFolderName sets the name of the folder that contains the chord set folders ("sets" by default), e.g. "chords".
+// The folder itself is not treated as a set, and it is used as the sets folder in debug mode.
+func (c *Converter) SetSetsFolderName(name string) {
+	c.setsName = name
+}
+
+// SetOutputFileMode sets the permissions of the output files (0644 by default), e.g. 0664 for group-writable files.
+// Only the permission bits may be set.
+func (c *Converter) SetOutputFileMode(mode os.FileMode) {
+	c.fileMode = mode
+}
+
+// SetIncludeSubfolders sets whether MIDI files in subfolders of a set folder contribute to the set (true by default).
+// Chord numbers must be unique across the set folder and its subfolders; a duplicate number is an error.
+func (c *Converter) SetIncludeSubfolders(include bool) {
+	c.flatSets = !include
+}
+
+// SetSetFilter sets filepath.Match patterns that select set folders by name: if include patterns are given,
+// only folders matching at least one of them are processed, and folders matching an exclude pattern are never
+// processed (exclude takes precedence). Unlike ignore patterns, subfolders of filtered folders are still searched.
+func (c *Converter) SetSetFilter(include, exclude []string) {
+	c.includeSets = include
+	c.excludeSets = exclude
+}
+
+// SetChannelFilter restricts reading notes to the given MIDI channel (1-16). 0 (default) reads notes on all channels.
+func (c *Converter) SetChannelFilter(channel int) {
+	c.channel = channel
+}
+
+// SetKeepVelocity sets whether chords include the notes with their velocities (in the notesWithVelocity field)
+// in addition to the plain notes.
+func (c *Converter) SetKeepVelocity(keep bool) {
+	c.keepVelocity = keep
+}
+
+// SetEmptyChordName sets the placeholder name for empty chords. The {index} placeholder is replaced with
+// the chord number, e.g. "Pad {index}"; without it, the number is appended after a space, e.g. "Chd 1" (default).
+func (c *Converter) SetEmptyChordName(name string) {
+	c.emptyChordName = name
+}
+
+// SetOmitEmptyChords sets whether chords without notes (placeholders) are left out of the output,
+// producing variable-length chord sets.
+func (c *Converter) SetOmitEmptyChords(omit bool) {
+	c.omitEmptyChords = omit
+}
+
+// SetTranspose sets the interval in semitones by which all notes are shifted (negative values shift down).
+// Transposed notes are checked against the note range set by SetNoteRange.
+func (c *Converter) SetTranspose(semitones int) {
+	c.transpose = semitones
+}
+
+// SetNormalizeVoicing enables normalization of chord voicings: all notes are moved into the octave
+// starting at the lowest note, so chords are written in root position regardless of the source voicing.
+func (c *Converter) SetNormalizeVoicing(normalize bool) {
+	c.normalize = normalize
+}
+
+// SetAnnotateOctaveSpan sets whether chord names detected from the notes are suffixed with the number of octaves
+// by which wide voicings extend beyond one octave (see helpers.OctaveSpan), e.g. "Cmaj(+1oct)".
+func (c *Converter) SetAnnotateOctaveSpan(annotate bool) {
+	c.octaveSpan = annotate
+}
+
+// SetNoteNaming sets how note names are spelled in chord names detected from the notes and in messages about notes:
+// sharps or flats, and whether middle C is labeled C3 (default) or C4.
+func (c *Converter) SetNoteNaming(naming NoteNaming) {
+	c.noteNaming = naming
+}
+
+// SetAutoName enables naming chords by their notes (see DetectChordName). Chords which can't be recognized
+// keep their names. Chords without a name are always named by their notes.
+func (c *Converter) SetAutoName(autoName bool) {
+	c.autoName = autoName
+}
+
+// SetOutputWriter sets a writer to which all chord sets are written as a single JSON array instead of
+// separate files (e.g. os.Stdout). Passing nil restores writing files.
+func (c *Converter) SetOutputWriter(w io.Writer) {
+	c.outputWriter = w
+}
+
+// SetCombinedFile sets the path of a file to which all chord sets are written as a single JSON array,
+// in addition to the separate chord set files. An empty path disables the combined file.
+func (c *Converter) SetCombinedFile(path string) {
+	c.combinedFile = path
+}
+
+// SetDeterministicUUID enables UUIDs derived from the set name and chords (UUID version 5), so that
+// the output stays the same across runs unless the chords change. By default, UUIDs are random.
+func (c *Converter) SetDeterministicUUID(deterministic bool) {
+	c.stableUUID = deterministic
+}
+
+// SetNoteRemap sets a table of note values replaced after reading and transposing the notes, e.g. to match
+// the pad-to-note mapping of a controller (see LoadNoteRemap). If strict is true, notes missing from the table
+// are reported with a warning; they are always kept unchanged. Passing nil disables remapping.
+func (c *Converter) SetNoteRemap(remap map[int]int, strict bool) {
+	c.remap = remap
+	c.strictRemap = strict
+}
+
+// SetLenient sets whether the notes read from a corrupt (e.g. truncated) MIDI file before the corrupt part are used
+// instead of failing the file. A warning with the number of used notes is logged for each such file, as the chord
+// may be incomplete. Files without any readable notes still fail.
+func (c *Converter) SetLenient(lenient bool) {
+	c.lenient = lenient
+}
+
+// SetTimestamp sets whether each chord set includes the time of the conversion (in UTC) in the GeneratedAt field,
+// e.g. to track when a set was last regenerated.
+func (c *Converter) SetTimestamp(timestamp bool) {
+	c.timestamp = timestamp
+}
+
+// SetClock sets the function that returns the current time for timestamps and timings (see LastTimings),
+// e.g. a fixed time in tests.
+// Passing nil restores time.Now.
+func (c *Converter) SetClock(now Clock) {
+	if now == nil {
+		now = time.Now
+	}
+	c.now = now
+}
+
+// SetCompactJSON sets whether JSON output is written minified, without indentation and line breaks,
+// for smaller files. By default, JSON is indented with four spaces.
+func (c *Converter) SetCompactJSON(compact bool) {
+	c.compactJSON = compact
+}
+
+// SetDryRun sets the dry-run mode. In dry-run mode, all processing is performed,
+// but instead of writing JSON files, their target paths and sizes are printed.
+func (c *Converter) SetDryRun(dryRun bool) {
+	c.dryRun = dryRun
+}
+
+// LastSummary returns the statistics of the last run.
+func (c *Converter) LastSummary() Summary {
+	return c.summary
+}
+
+// Run performs the sequence of operations:
+// 1. Validates the configuration
+// 2. Determines the path for main folder with sets
+// 3. Processes chord set folders in main folder
+// 4. Removes duplicate chord sets (if enabled)
+// 5. Outputs JSON files
+// 6. Reports files skipped due to errors in continue-on-error mode
+func (c *Converter) Run() error {
+	return c.RunContext(context.Background())
+}
+
+// RunContext is like Run, but stops when the context is cancelled and returns an error wrapping the context error.
+// The cancellation is checked before each set folder and each file. No files are written after cancellation.
+func (c *Converter) RunContext(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	if err := c.getSetsFolder(); err != nil {
+		return err
+	}
+
+	outDir := c.setsFolder // same folder
+	if c.debug {
+		outDir = filepath.Dir(c.setsFolder) // one level above
+	}
+
+	fsys, osRoot := c.inputFS()
+
+	return c.run(ctx, fsys, ".", osRoot, outDir)
+}
+
+// RunStream is like Run, but instead of writing output files it calls fn with each chord set as soon as
+// the set and all sets before it are processed, in the order of the sets. Duplicate sets and empty chords
+// are removed as configured. If fn returns an error, processing stops and the error is returned.
+func (c *Converter) RunStream(fn func(ChordSet) error) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	if err := c.getSetsFolder(); err != nil {
+		return err
+	}
+
+	fsys, osRoot := c.inputFS()
+	if err := c.stream(context.Background(), fsys, ".", osRoot, "", fn); err != nil {
+		return err
+	}
+
+	return c.fileErrsError()
+}
+
+// RunFS is like Run, but reads the chord sets from the folder root of the file system fsys (e.g. an embed.FS).
+// The output folder (or an output writer) must be set, unless in dry run mode.
+func (c *Converter) RunFS(fsys fs.FS, root string) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	if c.outputFolder == "" && c.outputWriter == nil && !c.dryRun {
+		return fmt.Errorf("output folder is not set")
+	}
+
+	return c.run(context.Background(), fsys, root, "", "")
+}
+
+// ConvertFolder converts the chord sets in the folder root of the file system fsys and returns them without writing
+// any files, e.g. to embed the conversion in another program or to benchmark it. In continue-on-error mode,
+// the sets are returned together with the error that lists the skipped files.
+func (c *Converter) ConvertFolder(fsys fs.FS, root string) ([]ChordSet, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := c.convert(context.Background(), fsys, root, "", ""); err != nil {
+		return nil, err
+	}
+
+	return c.chordSets, c.fileErrsError()
+}
+
+// RunZip is like Run, but reads the chord sets from a zip archive, treating its folders as set folders.
+// If the output folder is not set, the files are written next to the archive.
+func (c *Converter) RunZip(zipPath string) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("error opening zip archive %s: %w", zipPath, err)
+	}
+	defer zr.Close()
+
+	return c.run(context.Background(), zr, ".", zipPath, filepath.Dir(zipPath))
+}
+
+// run processes the chord sets in the folder root of fsys and writes the output files.
+// osRoot is the path of root in the OS file system (or of the archive), used to report file paths
+// (empty for other file systems). outDir is the folder for output files if the output folder is not set.
+func (c *Converter) run(ctx context.Context, fsys fs.FS, root, osRoot, outDir string) error {
+	start := c.now()
+	defer func() {
+		c.timings.Total = c.now().Sub(start)
+		c.logger.Debug("run finished", "duration", c.timings.Total)
+	}()
+
+	if err := c.convert(ctx, fsys, root, osRoot, outDir); err != nil {
+		return err
+	}
+
+	if err := c.outputFiles(); err != nil {
+		return err
+	}
+
+	if c.combinedFile != "" {
+		if err := c.writeCombinedFile(); err != nil {
+			return err
+		}
+	}
+
+	return c.fileErrsError()
+}
+
+// convert processes the chord sets in the folder root of fsys (see run) and stores them in chordSets.
+func (c *Converter) convert(ctx context.Context, fsys fs.FS, root, osRoot, outDir string) error {
+	c.chordSets = nil
+
+	return c.stream(ctx, fsys, root, osRoot, outDir, func(set ChordSet) error {
+		c.chordSets = append(c.chordSets, set)
+		return nil
+	})
+}
+
+// stream processes the chord sets in the folder root of fsys (see run) and calls fn with each set in order,
+// leaving out duplicate sets and empty chords as configured.
+func (c *Converter) stream(ctx context.Context, fsys fs.FS, root, osRoot, outDir string, fn func(ChordSet) error) error {
+	c.summary = Summary{}
+	c.timings = Timings{}
+	c.fileErrs = nil
+	c.issues = nil
+	c.fsys, c.fsRoot, c.osRoot, c.outDir = fsys, root, osRoot, outDir
+	c.nameRe = fileNameRegexp(c.separators)
+	c.outPattern = c.outputFilePattern()
+
+	start := c.now()
+	defer func() { c.timings.Total = c.now().Sub(start) }()
+
+	var unique []ChordSet // sets passed to fn so far, to detect duplicates
+	err := c.processSetsFolder(ctx, func(set ChordSet) error {
+		if c.dedupeSets {
+			idx := slices.IndexFunc(unique, func(u ChordSet) bool { return chordsEqual(u.Chords, set.Chords) })
+			if idx >= 0 {
+				c.logger.Info("duplicate set skipped", "name", set.Name, "duplicate_of", unique[idx].Name)
+				return nil
+			}
+			unique = append(unique, set)
+		}
+
+		if c.omitEmptyChords {
+			set.Chords = nonEmptyChords(set.Chords)
+			if len(set.Chords) == 0 {
+				c.logger.Warn("all chords are empty, set has no chords", "name", set.Name)
+			}
+		}
+
+		if c.transform != nil {
+			transformed, err := c.transform(set)
+			if errors.Is(err, ErrSkipSet) {
+				c.logger.Info("set skipped by transform", "name", set.Name)
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("error transforming set %s: %w", set.Name, err)
+			}
+			set = transformed
+		}
+
+		return fn(set)
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.Err()
+}
+
+// fileErrsError returns an error joining the errors of files skipped in continue-on-error mode, or nil if there are none.
+func (c *Converter) fileErrsError() error {
+	if len(c.fileErrs) > 0 {
+		return fmt.Errorf("%d files skipped due to errors:\n%w", len(c.fileErrs), errors.Join(c.fileErrs...))
+	}
+
+	return nil
+}
+
+// validate checks that the Converter configuration is consistent.
+func (c *Converter) validate() error {
+	if c.baseNote < minMidiNote || c.baseNote > maxMidiNote {
+		return fmt.Errorf("invalid base note %d: must be in range %d-%d", c.baseNote, minMidiNote, maxMidiNote)
+	}
+
+	if c.maxChords < minChordNumber {
+		return fmt.Errorf("invalid max chords %d: must be at least %d", c.maxChords, minChordNumber)
+	}
+
+	if c.numberBase < 0 || c.numberBase+c.maxChords-1 > maxFileChordNumber {
+		return fmt.Errorf("invalid chord number base %d: chord numbers %d..%d must be in range 0..%d",
+			c.numberBase, c.numberBase, c.numberBase+c.maxChords-1, maxFileChordNumber)
+	}
+
+	for _, r := range c.separators {
+		if !strings.ContainsRune(nameSeparators, r) {
+			return fmt.Errorf("invalid file name separator %q: must be one of %q", r, nameSeparators)
+		}
+	}
+
+	if c.minRelNote > c.maxRelNote {
+		return fmt.Errorf("invalid note range %d..%d: min is greater than max", c.minRelNote, c.maxRelNote)
+	}
+
+	if err := c.validateRemap(); err != nil {
+		return err
+	}
+
+	if c.rangePolicy == NoteRangeFold && c.maxRelNote-c.minRelNote < 11 {
+		return fmt.Errorf("invalid note range %d..%d: must span at least an octave to fold notes into it", c.minRelNote, c.maxRelNote)
+	}
+
+	if c.transpose < -maxMidiNote || c.transpose > maxMidiNote {
+		return fmt.Errorf("invalid transpose %d: must be in range -%d..%d", c.transpose, maxMidiNote, maxMidiNote)
+	}
+
+	if c.typeID != "" && !isKnownTypeID(c.typeID) {
+		c.logger.Warn("custom type id is not known to Maschine", "typeId", c.typeID)
+	}
+
+	if _, err := lookupSchema(c.schemaVer); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(c.emptyChordName) == "" {
+		return fmt.Errorf("placeholder name for empty chords is not set")
+	}
+
+	if c.outputTemplate == "" {
+		return fmt.Errorf("output file name template is empty")
+	}
+	if !strings.Contains(c.outputTemplate, "{index}") && !strings.Contains(c.outputTemplate, "{name}") {
+		return fmt.Errorf("output file name template %q must contain {index} or {name}", c.outputTemplate)
+	}
+	if c.cleanOutput && strings.Contains(c.outputTemplate, "{name}") {
+		return fmt.Errorf("stale output files can't be removed with the {name} placeholder in the output template %q",
+			c.outputTemplate)
+	}
+
+	if c.maxNotes < 0 {
+		return fmt.Errorf("invalid max notes per chord %d: must not be negative", c.maxNotes)
+	}
+
+	if c.setsName == "" || strings.ContainsAny(c.setsName, `/\`) {
+		return fmt.Errorf("invalid sets folder name %q: must be a non-empty folder name", c.setsName)
+	}
+
+	if c.fileMode&^os.ModePerm != 0 {
+		return fmt.Errorf("invalid output file mode %v: only permission bits are allowed", c.fileMode)
+	}
+
+	if c.maxNameLen < 0 {
+		return fmt.Errorf("invalid max set folder name length %d: must not be negative", c.maxNameLen)
+	}
+
+	if c.maxDepth < 0 {
+		return fmt.Errorf("invalid max depth %d: must not be negative", c.maxDepth)
+	}
+
+	for _, pattern := range c.ignorePatterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid ignore pattern %q: %w", pattern, err)
+		}
+	}
+
+	for _, pattern := range slices.Concat(c.includeSets, c.excludeSets) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid set filter pattern %q: %w", pattern, err)
+		}
+	}
+
+	if c.channel < allChannels || c.channel > maxMidiChannel {
+		return fmt.Errorf("invalid channel %d: must be in range 1-%d or 0 for all channels", c.channel, maxMidiChannel)
+	}
+
+	if c.workers < 1 {
+		return fmt.Errorf("invalid number of workers %d: must be at least 1", c.workers)
+	}
+
+	if len(c.extensions) == 0 {
+		return fmt.Errorf("no MIDI file extensions configured")
+	}
+
+	if c.maxSets < 1 {
+		return fmt.Errorf("invalid max sets %d: must be at least 1", c.maxSets)
+	}
+
+	return nil
+}
+
+// getSetsFolder determines the directory of the executable and sets the setsFolder path.
+// If debug mode is enabled, it uses the local "./sets" directory.
+// If the folders were set explicitly via SetSetsFolder or SetSetsFolders, they are only checked to be existing directories.
+func (c *Converter) getSetsFolder() error {
+	if c.setsFolder != "" {
+		for _, folder := range c.inputFolders() {
+			info, err := os.Stat(folder)
+			if err != nil {
+				return fmt.Errorf("error accessing sets folder %s: %w", folder, err)
+			}
+			if !info.IsDir() {
+				return fmt.Errorf("sets folder %s is not a directory", folder)
+			}
+		}
+
+		return nil
+	}
+
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("error determining executable path: %w", err)
+	}
+
+	c.setsFolder = filepath.Dir(execPath)
+
+	if c.debug {
+		c.setsFolder = c.setsName
+	}
+
+	return nil
+}
+
+// setFolder describes a chord set folder to be processed.
+type setFolder struct {
+	path    string    // path to the folder
+	name    string    // folder name, used as the chord set name
+	modTime time.Time // folder modification time
+}
+
+// ProgressFunc is called after each set folder is processed with the number of processed folders,
+// the total number of set folders and the name of the processed folder.
+type ProgressFunc func(done, total int, currentSet string)
+
+// Clock returns the current time.
+type Clock func() time.Time
+
+// TransformFunc post-processes a chord set before output and returns the set to be written.
+// Returning an error aborts the run, unless it wraps ErrSkipSet, in which case the set is left out.
+type TransformFunc func(set ChordSet) (ChordSet, error)
+
+// setResult holds the result of processing a single chord set folder.
+type setResult struct {
+	set      ChordSet      // processed chord set
+	summary  Summary       // statistics of the processed folder
+	fileErrs []error       // errors of files skipped in continue-on-error mode
+	issues   []Issue       // issues found in the folder
+	skipped  bool          // true if the set has no chords and is skipped
+	duration time.Duration // time spent processing the folder
+	err      error         // processing error, if any
+}
+
+// inputFolders returns the setsFolder followed by the additional input folders set with SetSetsFolders.
+func (c *Converter) inputFolders() []string {
+	return append([]string{c.setsFolder}, c.moreInputs...)
+}
+
+// inputFS returns the file system from which the chord sets are read and its OS path: the setsFolder, or the union
+// of all input folders if several are set (with an empty OS path, since paths are reported by the union itself).
+func (c *Converter) inputFS() (fs.FS, string) {
+	if len(c.moreInputs) == 0 {
+		return os.DirFS(c.setsFolder), c.setsFolder
+	}
+
+	return newMultiFS(c.inputFolders()), ""
+}
+
+// processSetsFolder scans the setsFolder directory for subfolders with valid names and processes each of them as a chord set.
+// Set folders are processed concurrently by a bounded pool of workers. The resulting chord sets are passed to fn
+// in the order in which the folders were found, each as soon as it and all preceding sets are processed.
+func (c *Converter) processSetsFolder(ctx context.Context, fn func(ChordSet) error) error {
+	folders, err := c.findSetFolders()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	results := make([]setResult, len(folders))
+	finished := make([]chan struct{}, len(folders)) // closed when the result with the same index is ready
+	for i := range finished {
+		finished[i] = make(chan struct{})
+	}
+	jobs := make(chan int)
+
+	var mu sync.Mutex // serializes progress reports
+	done := 0
+
+	var wg sync.WaitGroup
+	for range min(c.workers, len(folders)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[i].err = err
+					close(finished[i])
+					continue
+				}
+				start := c.now()
+				results[i] = c.processOneSetFolder(ctx, folders[i])
+				results[i].duration = c.now().Sub(start)
+				close(finished[i])
+
+				if c.progress != nil {
+					mu.Lock()
+					done++
+					c.progress(done, len(folders), folders[i].name)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	defer wg.Wait() // runs after cancel, so that the remaining folders are skipped quickly
+	defer cancel()
+
+	go func() {
+		defer close(jobs)
+		for i := range folders {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for i := range folders {
+		select {
+		case <-finished[i]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		result := results[i]
+		name := helpers.NormalizeName(folders[i].name)
+		c.timings.Sets = append(c.timings.Sets, SetTiming{Set: name, Duration: result.duration})
+		c.logger.Debug("set processed", "name", name, "duration", result.duration)
+		c.summary.add(result.summary)
+		c.fileErrs = append(c.fileErrs, result.fileErrs...)
+		c.issues = append(c.issues, result.issues...)
+		if result.err != nil {
+			return fmt.Errorf("error processing set folder %s: %w", c.displayPath(folders[i].path), result.err)
+		}
+		if result.skipped {
+			continue
+		}
+		if err := fn(result.set); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findSetFolders scans the setsFolder directory for subfolders with valid names and sorts them by the set order.
+// The Nth folder in this order becomes the Nth chord set. Folders beyond the maximum number of sets are ignored with a warning.
+func (c *Converter) findSetFolders() ([]setFolder, error) {
+	var folders []setFolder
+
+	if err := walkDirSorted(c.fsys, c.fsRoot, func(path string, dir fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !dir.IsDir() || path == c.fsRoot {
+			return nil
+		}
+
+		// don't descend below the maximum depth and into hidden or ignored folders
+		if c.maxDepth > 0 && c.folderDepth(path) > c.maxDepth {
+			return fs.SkipDir
+		}
+		if c.isIgnoredFolder(dir.Name()) {
+			return fs.SkipDir
+		}
+
+		// only process directories whose names are within the allowed length
+		if c.maxNameLen > 0 && utf8.RuneCountInString(dir.Name()) > c.maxNameLen {
+			c.logger.Debug("folder name is too long, skipped", "folder", c.displayPath(path), "max", c.maxNameLen)
+			return nil
+		}
+
+		if dir.Name() == c.setsName || !c.isSelectedSet(dir.Name()) {
+			return nil
+		}
+
+		info, err := dir.Info()
+		if err != nil {
+			return err
+		}
+
+		folders = append(folders, setFolder{path: path, name: dir.Name(), modTime: info.ModTime()})
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("directory traversal error: %w", err)
+	}
+
+	if err := c.resolveSetConflicts(folders); err != nil {
+		return nil, err
+	}
+
+	c.sortSetFolders(folders)
+
+	if len(folders) > c.maxSets {
+		ignored := make([]string, 0, len(folders)-c.maxSets)
+		for _, folder := range folders[c.maxSets:] {
+			ignored = append(ignored, folder.name)
+		}
+		if c.strictSets {
+			return nil, fmt.Errorf("%w: found %d, the maximum is %d, folders beyond the maximum: %s",
+				ErrTooManySets, len(folders), c.maxSets, strings.Join(ignored, ", "))
+		}
+		c.logger.Warn("maximum number of sets reached, folders ignored", "max", c.maxSets, "ignored", strings.Join(ignored, ", "))
+		for _, folder := range folders[c.maxSets:] {
+			c.issues = append(c.issues, Issue{Kind: IssueSetSkipped, Set: helpers.NormalizeName(folder.name),
+				File: c.displayPath(folder.path), Message: "maximum number of sets reached"})
+		}
+
+		folders = folders[:c.maxSets]
+	}
+
+	c.warnDuplicateSetNames(folders)
+
+	return folders, nil
+}
+
+// resolveSetConflicts handles set folders present in several input folders according to the set conflict policy.
+// It does nothing unless the chord sets are read from several input folders.
+func (c *Converter) resolveSetConflicts(folders []setFolder) error {
+	m, ok := c.fsys.(*multiFS)
+	if !ok {
+		return nil
+	}
+
+	for _, folder := range folders {
+		roots := m.dirRoots(folder.path)
+		if len(roots) < 2 {
+			continue
+		}
+
+		inputs := make([]string, 0, len(roots))
+		for _, i := range roots {
+			inputs = append(inputs, m.roots[i])
+		}
+
+		switch c.setConflict {
+		case SetConflictError:
+			return fmt.Errorf("set folder %s is present in several input folders: %s", folder.path, strings.Join(inputs, ", "))
+		case SetConflictMerge:
+			c.logger.Info("set folder merged from several input folders", "folder", folder.path, "inputs", strings.Join(inputs, ", "))
+		default:
+			m.pin(folder.path, roots[0])
+			c.logger.Warn("set folder is present in several input folders, using the first", "folder", folder.path,
+				"inputs", strings.Join(inputs, ", "))
+			c.issues = append(c.issues, Issue{Kind: IssueCollision, Set: helpers.NormalizeName(folder.name),
+				File: c.displayPath(folder.path), Message: "set folder is also present in " + strings.Join(inputs[1:], ", ")})
+		}
+	}
+
+	return nil
+}
+
+// warnDuplicateSetNames logs a warning for set folders whose names differ only in whitespace
+// (e.g. "Cmin" and "Cmin "), since they produce chord sets with the same name.
+func (c *Converter) warnDuplicateSetNames(folders []setFolder) {
+	seen := make(map[string]string, len(folders))
+	for _, folder := range folders {
+		name := helpers.NormalizeName(folder.name)
+		if first, ok := seen[name]; ok {
+			c.logger.Warn("set folders have the same name", "name", name,
+				"folders", c.displayPath(first)+", "+c.displayPath(folder.path))
+			c.issues = append(c.issues, Issue{Kind: IssueCollision, Set: name, File: c.displayPath(folder.path),
+				Message: "set folder has the same name as " + c.displayPath(first)})
+			continue
+		}
+		seen[name] = folder.path
+	}
+}
+
+// isIgnoredFolder reports whether the folder is hidden (its name starts with a dot) or matches an ignore pattern.
+func (c *Converter) isIgnoredFolder(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return true
+	}
+
+	return matchAny(c.ignorePatterns, name)
+}
+
+// isSelectedSet reports whether the set folder is selected by the include and exclude patterns.
+func (c *Converter) isSelectedSet(name string) bool {
+	if matchAny(c.excludeSets, name) {
+		return false
+	}
+
+	return len(c.includeSets) == 0 || matchAny(c.includeSets, name)
+}
+
+// matchAny reports whether the name matches at least one of the filepath.Match patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// folderDepth returns the depth of the folder below the setsFolder (1 for its direct subfolders).
+// A folder with the sets folder name (see SetSetsFolderName) directly inside the setsFolder is not counted as a level,
+// since it only contains set folders.
+func (c *Converter) folderDepth(path string) int {
+	parts := strings.Split(c.relPath(path), "/")
+	if parts[0] == c.setsName {
+		return len(parts) - 1
+	}
+
+	return len(parts)
+}
+
+// relPath returns the path of the file or folder relative to the fsRoot.
+func (c *Converter) relPath(path string) string {
+	if c.fsRoot == "." {
+		return path
+	}
+
+	return strings.TrimPrefix(path, c.fsRoot+"/")
+}
+
+// displayPath returns the path of the file or folder for messages: the OS path if the chord sets are read
+// from OS folders, otherwise the path within the file system.
+func (c *Converter) displayPath(path string) string {
+	if m, ok := c.fsys.(*multiFS); ok {
+		return m.osPath(c.relPath(path))
+	}
+	if c.osRoot == "" {
+		return path
+	}
+
+	return filepath.Join(c.osRoot, filepath.FromSlash(c.relPath(path)))
+}
+
+// sortSetFolders sorts set folders according to the set order. Folders with equal keys are ordered by path.
+func (c *Converter) sortSetFolders(folders []setFolder) {
+	slices.SortStableFunc(folders, func(a, b setFolder) int {
+		switch c.setOrder {
+		case SetOrderNameDesc:
+			if n := strings.Compare(b.name, a.name); n != 0 {
+				return n
+			}
+		case SetOrderModTime:
+			if n := a.modTime.Compare(b.modTime); n != 0 {
+				return n
+			}
+		default:
+			if n := strings.Compare(a.name, b.name); n != 0 {
+				return n
+			}
+		}
+
+		return strings.Compare(a.path, b.path)
+	})
+}
+
+// processOneSetFolder processes a single chord set folder.
+// It reads MIDI files, parses their names, extracts note data, and builds a ChordSet structure.
+// In continue-on-error mode, files that fail are logged and skipped instead of aborting the set.
+// It is safe for concurrent use, since it neither modifies nor copies the Converter state: settings overridden
+// by the set config (e.g. the base note) are passed down as arguments.
+func (c *Converter) processOneSetFolder(ctx context.Context, folder setFolder) setResult {
+	var result setResult
+
+	// the set.json file of the folder overrides the configuration for this set only.
+	config, hasConfig, err := c.readFolderConfig(folder.path)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	baseNote := c.baseNote
+	if config.BaseNote != nil {
+		baseNote = *config.BaseNote
+	}
+
+	setName := helpers.NormalizeName(folder.name)
+	if config.Name != "" {
+		setName = helpers.NormalizeName(config.Name)
+	}
+
+	c.logger.Info("processing set", "name", folder.name)
+	if hasConfig {
+		c.logger.Debug("set config applied", "folder", c.displayPath(folder.path), "name", setName, "base_note", baseNote)
+	}
+
+	// initialize the chords array with default values.
+	chords := make([]Chord, c.maxChords)
+	for i := range chords {
+		chords[i] = Chord{
+			Name:  c.placeholderName(i + 1),
+			Notes: []int{},
+			slot:  i + 1,
+		}
+	}
+
+	// chord file names by chord slot, used to detect duplicate numbers.
+	sources := make(map[int]string, c.maxChords)
+
+	// fail handles an error of a file according to the continue-on-error mode.
+	fail := func(chordPath string, err error) error {
+		result.summary.FilesWithErrors++
+		kind := IssueFileError
+		if errors.Is(err, ErrDuplicateNumber) {
+			kind = IssueCollision
+		}
+		result.issues = append(result.issues, Issue{Kind: kind, Set: setName, File: chordPath, Message: err.Error()})
+		if !c.continueOnError {
+			return err
+		}
+
+		c.logger.Error("file skipped", "file", chordPath, "error", err)
+		result.fileErrs = append(result.fileErrs, fmt.Errorf("%s: %w", chordPath, err))
+
+		return nil
+	}
+
+	// addChord reads the chord of the file and puts it into the chord slot of the chord number.
+	addChord := func(filePath, fileName string, chordNumber int, chordName string) error {
+		chordPath := c.displayPath(filePath)
+
+		var comment string
+		if c.splitComments {
+			chordName, comment = splitChordComment(chordName)
+		}
+
+		chordName, err := c.resolveChordName(filePath, chordName)
+		if err != nil {
+			return fail(chordPath, err)
+		}
+
+		// skip the file if the chord number is out of range.
+		lastNumber := c.numberBase + c.maxChords - 1
+		if chordNumber < c.numberBase || chordNumber > lastNumber {
+			result.summary.FilesSkipped++
+			result.issues = append(result.issues, Issue{Kind: IssueFileSkipped, Set: setName, File: chordPath,
+				Message: fmt.Sprintf("chord number %d is out of range %d..%d", chordNumber, c.numberBase, lastNumber)})
+			return nil
+		}
+		slot := chordNumber - c.numberBase + 1 // chord slot, starting from 1
+
+		if prev, ok := sources[slot]; ok {
+			return fail(chordPath, fmt.Errorf("%w %d: %s and %s", ErrDuplicateNumber, chordNumber, prev, fileName))
+		}
+
+		// read the chord notes from the MIDI file.
+		chordNotes, duplicates, err := c.readChordNotes(filePath, baseNote)
+		if err != nil {
+			return fail(chordPath, err)
+		}
+		result.summary.DuplicateNotes += duplicates
+
+		// skip the file if it contains no notes (e.g. only controller data), leaving the chord empty.
+		if len(chordNotes) == 0 {
+			c.logger.Warn("no notes found in file, skipped", "file", chordPath)
+			result.summary.FilesSkipped++
+			result.issues = append(result.issues, Issue{Kind: IssueFileSkipped, Set: setName, File: chordPath,
+				Message: "no notes found in file"})
+			return nil
+		}
+
+		if c.normalize {
+			chordNotes = normalizeVoicing(chordNotes)
+		}
+
+		chordNotes, err = c.applyNoteRange(fileName, chordNotes, baseNote)
+		if err != nil {
+			return fail(chordPath, err)
+		}
+
+		// skip the file if all its notes are out of range and skipped, leaving the chord empty.
+		if len(chordNotes) == 0 {
+			c.logger.Warn("all notes are out of range, skipped", "file", chordPath)
+			result.summary.FilesSkipped++
+			result.issues = append(result.issues, Issue{Kind: IssueFileSkipped, Set: setName, File: chordPath,
+				Message: "all notes are out of range"})
+			return nil
+		}
+		played := slices.Clone(chordNotes)
+		sortNotes(chordNotes)
+
+		chordNotes, err = c.applyNoteLimit(fileName, chordNotes)
+		if err != nil {
+			return fail(chordPath, err)
+		}
+		chordNotes = c.orderNotes(chordNotes, played)
+
+		// name the chord by its notes if auto-naming is enabled or the file provides no name.
+		if c.autoName || chordName == "" {
+			if detected := DetectChordNameWith(c.absoluteNotes(chordNotes, baseNote), c.noteNaming); detected != "" {
+				chordName = detected
+				if span := helpers.OctaveSpan(noteValues(chordNotes)); c.octaveSpan && span > 0 {
+					chordName += fmt.Sprintf("(+%doct)", span)
+				}
+			}
+		}
+
+		// skip the file if the chord name is empty and can't be detected.
+		if chordName == "" {
+			c.logger.Warn("chord name is empty and can't be detected, skipped", "file", chordPath)
+			result.summary.FilesSkipped++
+			result.issues = append(result.issues, Issue{Kind: IssueFileSkipped, Set: setName, File: chordPath,
+				Message: "chord name is empty and can't be detected"})
+			return nil
+		}
+
+		chord := Chord{
+			Name:    helpers.StripControlChars(chordName),
+			Notes:   noteValues(chordNotes),
+			Comment: helpers.StripControlChars(comment),
+			slot:    slot,
+		}
+		if c.keepVelocity {
+			chord.NotesWithVelocity = notesWithVelocity(chordNotes)
+		}
+		if c.trackSource {
+			chord.SourceFile = c.relPath(filePath)
+		}
+
+		c.logger.Debug("chord read", "file", chordPath, "number", chordNumber, "name", chord.Name, "notes", chord.Notes)
+
+		sources[slot] = fileName
+		chords[slot-1] = chord
+
+		return nil
+	}
+
+	// files without a chord number in auto-sequential numbering mode, numbered after the walk.
+	var unnumbered []string
+
+	// walk through the files in the chord set folder.
+	if err := walkDirSorted(c.fsys, folder.path, func(filePath string, file fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// files in subfolders contribute to the set unless disabled; hidden and ignored subfolders are skipped.
+		if file.IsDir() {
+			if filePath != folder.path && (c.flatSets || c.isIgnoredFolder(file.Name())) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		// skip files without a MIDI extension; the set config file and output files are not counted as skipped.
+		if isFolderConfig(folder.path, filePath) {
+			return nil
+		}
+		if c.isOutputFile(filePath) {
+			c.logger.Debug("generated file skipped", "file", c.displayPath(filePath))
+			return nil
+		}
+		if !c.isMidiFile(file.Name()) {
+			result.summary.FilesSkipped++
+			return nil
+		}
+
+		// parse the chord file name to extract the chord number and chord name.
+		chordNumber, chordName, err := parseChordFileName(c.nameRe, file.Name())
+		if err != nil {
+			if c.numbering == NumberingAutoSequential {
+				unnumbered = append(unnumbered, filePath)
+				return nil
+			}
+			return fail(c.displayPath(filePath), err)
+		}
 
-	"maschine_chords_converter/internal/helpers"
-)
+		return addChord(filePath, file.Name(), chordNumber, chordName)
+	}); err != nil {
+		result.err = fmt.Errorf("error processing set %s: %w", folder.name, err)
+		return result
+	}
 
-const (
-	version             = "1.0.0" // defines the current version of the chord sets
-	midiExtension       = ".mid"  // defines the required extension for MIDI files
-	baseChordName       = "Chd"   // is used for creating a default chord name (for empty chords)
-	baseNote            = 60      // the base note (C3) relative to which the note values will be calculated
-	maxSetFolderNameLen = 10      // defines the maximum length for a chord set folder name
-	minChordNumber      = 1       // the minimum allowed chord number
-	maxChordNumber      = 12      // the maximum allowed chord number (and, consequently, the number of chords in a set)
-	maxSetNumber        = 16      // the maximum number of chord sets that can be processed
-	setsFolderName      = "sets"  // folder name for chord sets
-)
+	// files without a chord number fill the free chord slots in the order of their paths.
+	slot := minChordNumber
+	for _, filePath := range unnumbered {
+		fileName := path.Base(filePath)
+		for slot <= c.maxChords && sources[slot] != "" {
+			slot++
+		}
+		if slot > c.maxChords {
+			result.summary.FilesSkipped++
+			result.issues = append(result.issues, Issue{Kind: IssueFileSkipped, Set: setName, File: c.displayPath(filePath),
+				Message: "no free chord slot for a file without a chord number"})
+			continue
+		}
+
+		chordName := strings.TrimSpace(strings.TrimSuffix(fileName, filepath.Ext(fileName)))
+		if err := addChord(filePath, fileName, slot+c.numberBase-1, chordName); err != nil {
+			result.err = fmt.Errorf("error processing set %s: %w", folder.name, err)
+			return result
+		}
+	}
 
-// re regular expression used to validate and parse MIDI file names. Expected file name format: "12 Amin9.mid" or "1 Cmin.mid"
-var re = regexp.MustCompile(`^(\d{1,2}) (.+?)\.mid$`)
+	// a set without chords usually means the folder has no correctly named MIDI files.
+	if len(sources) == 0 {
+		if c.skipEmptySets {
+			c.logger.Warn("no chords found in set folder, set skipped", "folder", c.displayPath(folder.path))
+			result.issues = append(result.issues, Issue{Kind: IssueSetSkipped, Set: setName,
+				File: c.displayPath(folder.path), Message: "no chords found in set folder"})
+			result.skipped = true
+			return result
+		}
 
-// Chord represents a single chord.
-type Chord struct {
-	Name  string `json:"name"`  // name of a chord
-	Notes []int  `json:"notes"` // slice of chord notes
+		c.logger.Warn("no chords found in set folder, all chords are empty", "folder", c.displayPath(folder.path))
+	}
+
+	result.summary.SetsProcessed++
+	result.summary.ChordsPopulated += len(sources)
+	result.summary.ChordsEmpty += c.maxChords - len(sources)
+
+	var emptySlots []int
+	for number := minChordNumber; number <= c.maxChords; number++ {
+		if _, ok := sources[number]; !ok {
+			emptySlots = append(emptySlots, number)
+		}
+	}
+	if len(emptySlots) > 0 {
+		result.summary.EmptySlots = []SetEmptySlots{{Set: setName, Slots: emptySlots}}
+		result.issues = append(result.issues, Issue{Kind: IssueEmptyChords, Set: setName,
+			File: c.displayPath(folder.path), Message: "empty chord slots: " + joinNumbers(emptySlots)})
+
+		if c.contiguous {
+			missing := make([]int, 0, len(emptySlots)) // chord numbers of the empty slots in file names
+			for _, slot := range emptySlots {
+				missing = append(missing, slot+c.numberBase-1)
+			}
+			if !c.continueOnError {
+				result.err = fmt.Errorf("%w: %s", ErrMissingChords, joinNumbers(missing))
+				return result
+			}
+			c.logger.Warn("missing chord numbers", "folder", c.displayPath(folder.path), "numbers", joinNumbers(missing))
+		}
+	}
+
+	sch, err := lookupSchema(c.schemaVer)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	typeID := sch.typeID
+	if c.typeID != "" {
+		typeID = c.typeID
+	}
+	if config.TypeID != "" {
+		typeID = config.TypeID
+	}
+
+	result.set = ChordSet{
+		Chords:       chords,
+		Name:         setName,
+		TypeID:       typeID,
+		Version:      c.schemaVer,
+		numberOffset: c.numberBase - 1,
+	}
+	if c.timestamp {
+		result.set.GeneratedAt = c.now().UTC().Format(time.RFC3339)
+	}
+
+	result.set.UUID, err = c.setUUID(result.set)
+	if err != nil {
+		result.err = fmt.Errorf("error processing set %s: %w", folder.name, err)
+		return result
+	}
+
+	return result
 }
 
-// ChordSet represents a set of chords along with properties required for generating a JSON file.
-type ChordSet struct {
-	Chords  []Chord `json:"chords"`  // slice of chords
-	Name    string  `json:"name"`    // name of a set
-	TypeID  string  `json:"typeId"`  // metadata
-	UUID    string  `json:"uuid"`    // metadata
-	Version string  `json:"version"` // metadata
+// setUUID returns the UUID for the chord set: random by default, or derived from the set name and chords
+// if deterministic UUIDs are enabled.
+func (c *Converter) setUUID(set ChordSet) (string, error) {
+	if !c.stableUUID {
+		return helpers.GenerateUUID()
+	}
+
+	content, err := json.Marshal(set.Chords)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling chords of set %s: %w", set.Name, err)
+	}
+
+	return helpers.GenerateNameUUID(uuidNamespace, append([]byte(set.Name+"\x00"), content...)), nil
 }
 
-// Converter converts MIDI files into JSON chord sets.
-type Converter struct {
-	chordSets  []ChordSet // processed chord sets
-	setsFolder string     // path to the folder containing chord set directories
-	debug      bool       // debug mode flag
+// placeholderName returns the name of the empty chord with the given number.
+func (c *Converter) placeholderName(number int) string {
+	if strings.Contains(c.emptyChordName, "{index}") {
+		return strings.ReplaceAll(c.emptyChordName, "{index}", strconv.Itoa(number))
+	}
+
+	return fmt.Sprintf("%s %d", c.emptyChordName, number)
 }
 
-// New creates and returns a new Converter instance.
-func New() Converter {
-	return Converter{chordSets: make([]ChordSet, 0, maxSetNumber)}
+// isMidiFile reports whether the file name has one of the allowed MIDI extensions.
+func (c *Converter) isMidiFile(fileName string) bool {
+	ext := filepath.Ext(fileName)
+	for _, allowed := range c.extensions {
+		if strings.EqualFold(ext, allowed) {
+			return true
+		}
+	}
+
+	return false
 }
 
-// SetDebug sets the debug mode of the Converter instance.
-func (c *Converter) SetDebug(debug bool) {
-	c.debug = debug
+// fileNameRegexp returns the regular expression for MIDI file names (see re) that also accepts the given separators
+// between the chord number and the name. The separators must be validated (see nameSeparators).
+func fileNameRegexp(separators string) *regexp.Regexp {
+	if separators == "" {
+		return re
+	}
+
+	var class strings.Builder
+	for _, r := range separators {
+		class.WriteRune('\\')
+		class.WriteRune(r)
+	}
+
+	return regexp.MustCompile(`^(\d{1,3})[\s` + class.String() + `]+(.*)$`)
 }
 
-// Run performs the sequence of operations:
-// 1. Determines the path for main folder with sets
-// 2. Processes chord set folders in main folder
-// 3. Outputs JSON files
-func (c *Converter) Run() error {
-	if err := c.getSetsFolder(); err != nil {
-		return err
+// parseChordFileName parses a MIDI file name with the regular expression re (see fileNameRegexp)
+// and extracts the chord number and chord name.
+func parseChordFileName(re *regexp.Regexp, fileName string) (int, string, error) {
+	match := re.FindStringSubmatch(strings.TrimSuffix(fileName, filepath.Ext(fileName)))
+	if len(match) != re.NumSubexp()+1 { // ensure match length equals full match (1) + number of subexpressions (2)
+		return 0, "", fmt.Errorf("invalid file name: %s", fileName)
 	}
 
-	if err := c.processSetsFolder(); err != nil {
-		return err
+	number, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, "", fmt.Errorf("can't convert chord number to integer: %s", fileName)
 	}
 
-	if err := c.outputJsonFiles(); err != nil {
-		return err
+	name := strings.TrimSpace(match[2])
+
+	return number, name, nil
+}
+
+// splitChordComment splits a trailing parenthetical comment from the chord name,
+// e.g. "Cmaj7 (bright)" gives "Cmaj7" and "bright". Names without a comment are returned unchanged.
+func splitChordComment(name string) (string, string) {
+	match := commentRe.FindStringSubmatch(name)
+	if match == nil {
+		return name, ""
 	}
 
-	return nil
+	return match[1], strings.TrimSpace(match[2])
 }
 
-// getSetsFolder determines the directory of the executable and sets the setsFolder path.
-// If debug mode is enabled, it uses the local "./sets" directory.
-func (c *Converter) getSetsFolder() error {
-	execPath, err := os.Executable()
+// resolveChordName returns the chord name according to the name source,
+// using the name parsed from the file name and the name stored in the MIDI file.
+func (c *Converter) resolveChordName(path, fileChordName string) (string, error) {
+	if c.nameSource == NameSourceFilename || (c.nameSource == NameSourceFilenameThenMeta && fileChordName != "") {
+		return fileChordName, nil
+	}
+
+	data, err := fs.ReadFile(c.fsys, path)
 	if err != nil {
-		return fmt.Errorf("error determining executable path: %w", err)
+		return "", fmt.Errorf("failed to read MIDI file %s: %w", c.displayPath(path), err)
 	}
 
-	c.setsFolder = filepath.Dir(execPath)
+	// in lenient mode, the error is reported when the notes are read.
+	metaName, err := readMidiName(bytes.NewReader(data))
+	if err != nil && !c.lenient {
+		return "", fmt.Errorf("failed to read MIDI file %s: %w", c.displayPath(path), err)
+	}
 
-	if c.debug {
-		c.setsFolder = setsFolderName
+	if metaName == "" {
+		return fileChordName, nil
 	}
 
-	return nil
+	return metaName, nil
 }
 
-// processSetsFolder scans the setsFolder directory for subfolders with valid names and processes each of them as a chord set.
-func (c *Converter) processSetsFolder() error {
-	if err := filepath.WalkDir(c.setsFolder, func(path string, dir fs.DirEntry, err error) error {
-		if err != nil {
-			return err
+// applyNoteLimit handles chords with more notes than the maximum according to the note limit policy.
+// The notes must be sorted, so that truncation keeps the lowest notes.
+func (c *Converter) applyNoteLimit(fileName string, notes []midiNote) ([]midiNote, error) {
+	if c.maxNotes == 0 || len(notes) <= c.maxNotes {
+		return notes, nil
+	}
+
+	switch c.noteLimitPolicy {
+	case NoteLimitError:
+		return nil, fmt.Errorf("too many notes in file %s: %d (max %d)", fileName, len(notes), c.maxNotes)
+	case NoteLimitWarn:
+		c.logger.Warn("too many notes in chord", "file", fileName, "notes", len(notes), "max", c.maxNotes)
+		return notes, nil
+	default:
+		c.logger.Warn("too many notes in chord, highest notes dropped", "file", fileName, "notes", len(notes), "max", c.maxNotes)
+		return notes[:c.maxNotes], nil
+	}
+}
+
+// orderNotes arranges the sorted notes according to the note sort order.
+// played contains the notes in the order in which they were read, which decides between notes with the same onset.
+func (c *Converter) orderNotes(notes, played []midiNote) []midiNote {
+	switch c.noteSort {
+	case NoteSortDescending:
+		slices.Reverse(notes)
+	case NoteSortAsPlayed:
+		notes = slices.DeleteFunc(played, func(p midiNote) bool {
+			return !slices.ContainsFunc(notes, func(n midiNote) bool { return n.value == p.value })
+		})
+		slices.SortStableFunc(notes, func(a, b midiNote) int { return cmp.Compare(a.tick, b.tick) })
+	}
+
+	return notes
+}
+
+// applyNoteRange checks the note values against the allowed range and handles
+// out of range notes according to the note range policy. The base note is only used to name the notes in warnings.
+func (c *Converter) applyNoteRange(fileName string, notes []midiNote, baseNote int) ([]midiNote, error) {
+	if c.rangePolicy == NoteRangeIgnore {
+		return notes, nil
+	}
+
+	result := make([]midiNote, 0, len(notes))
+	for _, note := range notes {
+		if note.value >= c.minRelNote && note.value <= c.maxRelNote {
+			result = appendUniqueNote(result, note)
+			continue
 		}
 
-		// only process directories that are not the root folder and whose names are within the allowed length
-		if dir.IsDir() && path != c.setsFolder && len(dir.Name()) <= maxSetFolderNameLen {
-			if dir.Name() == setsFolderName {
-				return nil
-			}
+		switch c.rangePolicy {
+		case NoteRangeError:
+			return nil, fmt.Errorf("note %d in %s is out of range %d..%d", note.value, fileName, c.minRelNote, c.maxRelNote)
+		case NoteRangeSkip:
+			c.logger.Warn("note out of range, skipped", "file", fileName, "note", note.value, "pitch", c.noteName(note, baseNote), "min", c.minRelNote, "max", c.maxRelNote)
+		case NoteRangeFold:
+			folded := helpers.FoldNote(note.value, c.minRelNote, c.maxRelNote)
+			c.logger.Warn("note out of range, folded", "file", fileName, "note", note.value, "pitch", c.noteName(note, baseNote), "min", c.minRelNote, "max", c.maxRelNote, "folded", folded)
+			note.value = folded
+			result = appendUniqueNote(result, note)
+		case NoteRangeClamp:
+			clamped := min(max(note.value, c.minRelNote), c.maxRelNote)
+			c.logger.Warn("note out of range, clamped", "file", fileName, "note", note.value, "pitch", c.noteName(note, baseNote), "min", c.minRelNote, "max", c.maxRelNote, "clamped", clamped)
+			note.value = clamped
+			result = appendUniqueNote(result, note)
+		}
+	}
 
-			if err = c.processOneSetFolder(path, dir.Name()); err != nil {
-				return fmt.Errorf("error processing set folder %s: %w", path, err)
-			}
+	return result, nil
+}
+
+// chordsEqual reports whether two chord slices contain the same chords (names and notes) in the same order.
+func chordsEqual(a, b []Chord) bool {
+	return slices.EqualFunc(a, b, func(x, y Chord) bool {
+		return x.Name == y.Name && x.Comment == y.Comment && slices.Equal(x.Notes, y.Notes) &&
+			slices.Equal(x.NotesWithVelocity, y.NotesWithVelocity)
+	})
+}
+
+// nonEmptyChords returns the chords that have notes.
+func nonEmptyChords(chords []Chord) []Chord {
+	result := make([]Chord, 0, len(chords))
+	for _, chord := range chords {
+		if len(chord.Notes) > 0 {
+			result = append(result, chord)
 		}
+	}
 
-		return nil
-	}); err != nil {
-		return fmt.Errorf("directory traversal error: %w", err)
+	return result
+}
+
+// outputFileName renders the output file name template for the set with the given number and name.
+// It returns an error if the rendered name is empty or is not a plain file name.
+func (c *Converter) outputFileName(number int, name string) (string, error) {
+	fileName := strings.NewReplacer(
+		"{index}", fmt.Sprintf("%02d", number),
+		"{name}", helpers.SanitizeName(name),
+	).Replace(c.outputTemplate)
+
+	if strings.TrimSpace(fileName) == "" || fileName == "." || fileName == ".." || strings.ContainsAny(fileName, `/\`) {
+		return "", fmt.Errorf("invalid output file name %q rendered from template %q", fileName, c.outputTemplate)
 	}
 
-	return nil
+	return fileName, nil
 }
 
-// processOneSetFolder processes a single chord set folder.
-// It reads MIDI files, parses their names, extracts note data, and builds a ChordSet structure.
-func (c *Converter) processOneSetFolder(setPath, setName string) error {
-	fmt.Printf("processing set: %s\n", setName)
+// outputFileNames renders the output file names of the chord sets. All names are checked before any file is written:
+// a name rendered for several sets (e.g. from a template without {index} and sets whose names sanitize to the same
+// file name) is an error, since the later set would overwrite the earlier one.
+func (c *Converter) outputFileNames() ([]string, error) {
+	fileNames := make([]string, 0, len(c.chordSets))
+	sets := make(map[string]string, len(c.chordSets)) // set name by file name
+	for i, chordSet := range c.chordSets {
+		fileName, err := c.outputFileName(i+1, chordSet.Name)
+		if err != nil {
+			return nil, err
+		}
 
-	if len(c.chordSets) >= maxSetNumber {
-		return nil
+		if c.manifest && fileName == manifestFileName {
+			return nil, fmt.Errorf("output file name of set %s is the same as the manifest %s", chordSet.Name, manifestFileName)
+		}
+		if prev, ok := sets[fileName]; ok {
+			return nil, fmt.Errorf("sets %s and %s have the same output file name %s", prev, chordSet.Name, fileName)
+		}
+		sets[fileName] = chordSet.Name
+
+		fileNames = append(fileNames, fileName)
 	}
 
-	// initialize the chords array with default values.
-	chords := make([]Chord, maxChordNumber)
-	for i := range chords {
-		chords[i] = Chord{
-			Name:  fmt.Sprintf("%s %d", baseChordName, i+1),
-			Notes: []int{},
+	return fileNames, nil
+}
+
+// outputFiles generates and saves JSON files for each processed chord set (or a single CSV file in the CSV format).
+// The files are saved to the outputFolder if it is set, otherwise in the setsFolder
+// (one directory level above the setsFolder in debug mode, next to the archive for RunZip).
+func (c *Converter) outputFiles() error {
+	if c.outputWriter != nil {
+		if c.outputFormat == OutputFormatCSV {
+			return ExportCSV(c.chordSets, c.outputWriter)
 		}
+		return c.writeJsonStream()
 	}
 
-	// walk through the files in the chord set folder.
-	if err := filepath.WalkDir(setPath, func(chordPath string, file fs.DirEntry, err error) error {
-		if err != nil {
-			return err
+	outFolder := c.outDir
+	if c.outputFolder != "" {
+		outFolder = c.outputFolder
+	}
+
+	if c.outputFolder != "" && !c.dryRun {
+		if err := os.MkdirAll(outFolder, 0755); err != nil {
+			return fmt.Errorf("error creating output folder %s: %w", outFolder, err)
 		}
+	}
 
-		// skip directories and files without the .mid extension.
-		if file.IsDir() || !strings.HasSuffix(file.Name(), midiExtension) {
-			return nil
+	if c.outputFormat == OutputFormatCSV {
+		return c.writeCSVFile(filepath.Join(outFolder, csvFileName))
+	}
+
+	fileNames, err := c.outputFileNames()
+	if err != nil {
+		return err
+	}
+
+	written := make(map[string]bool, len(c.chordSets))
+	var entries []ManifestEntry
+	for i, chordSet := range c.chordSets {
+		jsonData, err := c.marshalJSON(encodedSet(chordSet))
+		if err != nil {
+			return fmt.Errorf("error marshaling JSON for %s: %w", chordSet.Name, err)
 		}
 
-		// parse the chord file name to extract the chord number and chord name.
-		chordNumber, chordName, err := c.parseChordFileName(file.Name())
+		fileName := fileNames[i]
+		written[fileName] = true
+		entries = append(entries, newManifestEntry(fileName, chordSet))
+
+		outFile, err := containedPath(outFolder, fileName)
 		if err != nil {
 			return err
 		}
+		if c.dryRun {
+			c.logger.Info("dry run: would write file", "path", outFile, "bytes", len(jsonData))
+			continue
+		}
 
-		// skip the file if the chord number is out of range or if the chord name is empty.
-		if chordNumber < minChordNumber || chordNumber > maxChordNumber || chordNumber == 0 || chordName == "" {
-			return nil
+		if err = c.writeOutputFile(outFile, jsonData); err != nil {
+			return fmt.Errorf("error writing JSON file %s: %w", outFile, err)
 		}
 
-		// read the chord notes from the MIDI file.
-		chordNotes, err := c.readChordNotes(chordPath)
-		if err != nil {
+		c.logger.Info("generated file", "path", outFile)
+	}
+
+	if c.manifest {
+		written[manifestFileName] = true
+		if err := c.writeManifest(outFolder, entries); err != nil {
 			return err
 		}
-		slices.Sort(chordNotes)
+	}
+
+	return c.handleStaleFiles(outFolder, written)
+}
+
+// containedPath joins the output folder and the file name and checks that the resulting path is within the folder,
+// so that a file name rendered from a set name (e.g. with "..") can't make a file be written elsewhere.
+func containedPath(folder, fileName string) (string, error) {
+	path := filepath.Join(folder, fileName)
+
+	absFolder, err := filepath.Abs(folder)
+	if err != nil {
+		return "", fmt.Errorf("error resolving output folder %s: %w", folder, err)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("error resolving output file %s: %w", path, err)
+	}
+
+	rel, err := filepath.Rel(absFolder, absPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return "", fmt.Errorf("output file %q is outside the output folder %s", fileName, folder)
+	}
+
+	return path, nil
+}
 
-		chords[chordNumber-1] = Chord{
-			Name:  chordName,
-			Notes: chordNotes,
+// isOutputFile reports whether the file read from the sets folder is a file generated by the utility, i.e. a file
+// in the output folder matching the output file name pattern, the CSV file, the manifest or the combined file. Such files are never
+// read as input, even if the output file name template produces MIDI file names.
+func (c *Converter) isOutputFile(filePath string) bool {
+	if c.osRoot == "" {
+		if _, ok := c.fsys.(*multiFS); !ok {
+			return false // not an OS folder, so it can't contain the output files
 		}
+	}
+
+	osPath := c.displayPath(filePath)
+	if c.isCombinedFile(osPath) {
+		return true
+	}
+
+	outFolder := c.outDir
+	if c.outputFolder != "" {
+		outFolder = c.outputFolder
+	}
+	if outFolder == "" {
+		return false
+	}
+
+	dir, err1 := filepath.Abs(filepath.Dir(osPath))
+	out, err2 := filepath.Abs(outFolder)
+	if err1 != nil || err2 != nil || dir != out {
+		return false
+	}
+
+	name := filepath.Base(osPath)
+	if name == manifestFileName {
+		return true
+	}
+	if c.outputFormat == OutputFormatCSV {
+		return name == csvFileName
+	}
+
+	return c.outPattern != nil && c.outPattern.MatchString(name)
+}
+
+// isCombinedFile reports whether the path refers to the combined file.
+func (c *Converter) isCombinedFile(path string) bool {
+	if c.combinedFile == "" {
+		return false
+	}
 
+	combined, err1 := filepath.Abs(c.combinedFile)
+	file, err2 := filepath.Abs(path)
+
+	return err1 == nil && err2 == nil && combined == file
+}
+
+// outputFilePattern returns a regular expression matching the file names rendered from the output template.
+func (c *Converter) outputFilePattern() *regexp.Regexp {
+	pattern := regexp.QuoteMeta(c.outputTemplate)
+	pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta("{index}"), `\d{2,}`)
+	pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta("{name}"), `.+`)
+
+	return regexp.MustCompile("^" + pattern + "$")
+}
+
+// handleStaleFiles finds files in the output folder that match the output template, but were not written
+// in this run (e.g. left from a previous run with more sets). They are removed in clean output mode,
+// otherwise a warning is logged. With the {name} placeholder in the template, stale files can't be told apart
+// from other files (e.g. any JSON file matches "{name}.json"), so they are not looked for.
+func (c *Converter) handleStaleFiles(outFolder string, written map[string]bool) error {
+	if strings.Contains(c.outputTemplate, "{name}") {
 		return nil
-	}); err != nil {
-		return fmt.Errorf("error processing set %s: %w", setName, err)
 	}
 
-	// append the processed chord set to the list.
-	c.chordSets = append(c.chordSets, ChordSet{
-		Chords:  chords,
-		Name:    setName,
-		UUID:    helpers.GenerateUUID(),
-		TypeID:  "native-instruments-chord-set",
-		Version: version,
-	})
+	entries, err := os.ReadDir(outFolder)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("error reading output folder %s: %w", outFolder, err)
+	}
+
+	pattern := c.outputFilePattern()
+	for _, entry := range entries {
+		if entry.IsDir() || written[entry.Name()] || !pattern.MatchString(entry.Name()) {
+			continue
+		}
+
+		staleFile := filepath.Join(outFolder, entry.Name())
+		if c.isCombinedFile(staleFile) {
+			continue
+		}
+
+		switch {
+		case !c.cleanOutput:
+			c.logger.Warn("stale output file from a previous run", "path", staleFile)
+		case c.dryRun:
+			c.logger.Info("dry run: would remove stale file", "path", staleFile)
+		default:
+			if err := os.Remove(staleFile); err != nil {
+				return fmt.Errorf("error removing stale file %s: %w", staleFile, err)
+			}
+			c.logger.Info("removed stale file", "path", staleFile)
+		}
+	}
 
 	return nil
 }
 
-// parseChordFileName parses a MIDI file name and extracts the chord number and chord name.
-func (c *Converter) parseChordFileName(fileName string) (int, string, error) {
-	match := re.FindStringSubmatch(fileName)
-	if len(match) != re.NumSubexp()+1 { // ensure match length equals full match (1) + number of subexpressions (2)
-		return 0, "", fmt.Errorf("invalid file name: %s", fileName)
+// writeOutputFile writes the data to the file and sets the output file mode. The mode is set explicitly,
+// so that it is not restricted by the umask.
+func (c *Converter) writeOutputFile(path string, data []byte) error {
+	if err := os.WriteFile(path, data, c.fileMode); err != nil {
+		return err
 	}
 
-	number, err := strconv.Atoi(match[1])
-	if err != nil {
-		return 0, "", fmt.Errorf("can't convert chord number to integer: %s", fileName)
+	return os.Chmod(path, c.fileMode)
+}
+
+// writeCSVFile writes all chord sets to a CSV file (see ExportCSV).
+func (c *Converter) writeCSVFile(path string) error {
+	var buf bytes.Buffer
+	if err := ExportCSV(c.chordSets, &buf); err != nil {
+		return err
 	}
 
-	name := strings.TrimSpace(match[2])
+	if c.dryRun {
+		c.logger.Info("dry run: would write file", "path", path, "bytes", buf.Len())
+		return nil
+	}
 
-	return number, name, nil
+	if err := c.writeOutputFile(path, buf.Bytes()); err != nil {
+		return fmt.Errorf("error writing CSV file %s: %w", path, err)
+	}
+
+	c.logger.Info("generated file", "path", path)
+
+	return nil
 }
 
-// readChordNotes reads notes from a MIDI file and returns a slice of relative to baseNote note values.
-func (c *Converter) readChordNotes(path string) ([]int, error) {
-	var notes []int
-	seen := make(map[int]bool)
+// writeJsonStream writes all chord sets as a single JSON array to the output writer.
+func (c *Converter) writeJsonStream() error {
+	jsonData, err := c.MarshalAll()
+	if err != nil {
+		return err
+	}
+
+	if _, err = fmt.Fprintf(c.outputWriter, "%s\n", jsonData); err != nil {
+		return fmt.Errorf("error writing JSON: %w", err)
+	}
 
-	rd := reader.New(
-		reader.NoLogger(),
-		reader.NoteOn(func(pos *reader.Position, channel, key, vel uint8) {
-			if vel > 0 && !seen[int(key)] {
-				notes = append(notes, int(key))
-				seen[int(key)] = true
-			}
-		}),
-	)
+	return nil
+}
 
-	err := reader.ReadSMFFile(rd, path)
+// writeCombinedFile writes all chord sets as a single JSON array to the combined file.
+func (c *Converter) writeCombinedFile() error {
+	jsonData, err := c.MarshalAll()
 	if err != nil {
-		return notes, fmt.Errorf("failed to read MIDI file %s: %w", path, err)
+		return err
 	}
 
-	var relative []int
-	for _, note := range notes {
-		relative = append(relative, note-baseNote)
+	if c.dryRun {
+		c.logger.Info("dry run: would write file", "path", c.combinedFile, "bytes", len(jsonData))
+		return nil
 	}
 
-	return relative, nil
+	if err = c.writeOutputFile(c.combinedFile, jsonData); err != nil {
+		return fmt.Errorf("error writing JSON file %s: %w", c.combinedFile, err)
+	}
+
+	c.logger.Info("generated file", "path", c.combinedFile)
+
+	return nil
 }
 
-// outputJsonFiles generates and saves JSON files for each processed chord set.
-// The JSON files are saved one directory level above the setsFolder.
-func (c *Converter) outputJsonFiles() error {
-	for i, chordSet := range c.chordSets {
-		jsonData, err := json.MarshalIndent(chordSet, "", "    ")
-		if err != nil {
-			return fmt.Errorf("error marshaling JSON for %s: %w", chordSet.Name, err)
-		}
+// MarshalAll returns the chord sets of the last run as a JSON array, in the same order and with
+// the same content as the separate chord set files.
+func (c *Converter) MarshalAll() ([]byte, error) {
+	sets := make([]any, 0, len(c.chordSets))
+	for _, set := range c.chordSets {
+		sets = append(sets, encodedSet(set))
+	}
 
-		outFolder := c.setsFolder // same folder
-		if c.debug {
-			outFolder = filepath.Dir(c.setsFolder) // one level above
-		}
+	jsonData, err := c.marshalJSON(sets)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling JSON: %w", err)
+	}
 
-		outFile := filepath.Join(outFolder, fmt.Sprintf("user_chord_set_0%d.json", i+1))
-		if err = os.WriteFile(outFile, jsonData, 0644); err != nil {
-			return fmt.Errorf("error writing JSON file %s: %w", outFile, err)
-		}
+	return jsonData, nil
+}
 
-		fmt.Println("generated file:", outFile)
+// marshalJSON returns the JSON encoding of v, indented with four spaces unless compact JSON is enabled.
+func (c *Converter) marshalJSON(v any) ([]byte, error) {
+	if c.compactJSON {
+		return json.Marshal(v)
 	}
 
-	return nil
+	return json.MarshalIndent(v, "", "    ")
 }
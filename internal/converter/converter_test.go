@@ -0,0 +1,1043 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"gitlab.com/gomidi/midi/writer"
+)
+
+func TestParallelOrderMatchesSerial(t *testing.T) {
+	fsys := fixtureTree(t, 16, 12)
+
+	serial := testConverter()
+	serial.SetWorkers(1)
+	want := convertFS(t, &serial, fsys)
+
+	parallel := testConverter()
+	parallel.SetWorkers(8)
+	got := convertFS(t, &parallel, fsys)
+
+	if names := setNames(got); !slices.IsSorted(names) {
+		t.Errorf("set order = %q, want sorted by folder name", names)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d sets, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || !reflect.DeepEqual(got[i].Chords, want[i].Chords) {
+			t.Errorf("set %d = %s, want %s", i, got[i].Name, want[i].Name)
+		}
+	}
+}
+
+func BenchmarkConvertFolderWorkers(b *testing.B) {
+	fsys := fixtureTree(b, 16, 12)
+
+	for _, bb := range []struct {
+		name    string
+		workers int
+	}{
+		{"serial", 1},
+		{"parallel", runtime.NumCPU()},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			c := testConverter()
+			c.SetWorkers(bb.workers)
+			for b.Loop() {
+				if _, err := c.ConvertFolder(fsys, "."); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertFolderWritesNothing(t *testing.T) {
+	root := writeTree(t, fixtureTree(t, 2, 12))
+
+	c := testConverter()
+	sets := convertFS(t, &c, os.DirFS(root))
+
+	if len(sets) != 2 {
+		t.Fatalf("got %d sets, want 2", len(sets))
+	}
+	if got, want := dirNames(t, root), []string{"Set 01", "Set 02"}; !slices.Equal(got, want) {
+		t.Errorf("folder entries = %q, want %q", got, want)
+	}
+}
+
+func BenchmarkConvertFolder(b *testing.B) {
+	fsys := fixtureTree(b, 16, 12)
+	c := testConverter()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := c.ConvertFolder(fsys, "."); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDuplicateChordNumber(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/3 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"Set/3 Dmin.mid": smfFile(smfChord(t, 62, 65, 69)),
+	}
+
+	c := testConverter()
+	_, err := c.ConvertFolder(fsys, ".")
+	if !errors.Is(err, ErrDuplicateNumber) {
+		t.Fatalf("ConvertFolder() error = %v, want %v", err, ErrDuplicateNumber)
+	}
+	if !strings.Contains(err.Error(), "3 Cmaj.mid") || !strings.Contains(err.Error(), "3 Dmin.mid") {
+		t.Errorf("error %q doesn't name both files", err)
+	}
+}
+
+func TestDuplicateChordNumberContinueOnError(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/3 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"Set/3 Dmin.mid": smfFile(smfChord(t, 62, 65, 69)),
+	}
+
+	c := testConverter()
+	c.SetContinueOnError(true)
+	sets, err := c.ConvertFolder(fsys, ".")
+	if !errors.Is(err, ErrDuplicateNumber) {
+		t.Fatalf("ConvertFolder() error = %v, want %v", err, ErrDuplicateNumber)
+	}
+	if len(sets) != 1 || sets[0].Chords[2].Name != "Cmaj" {
+		t.Fatalf("sets = %+v, want chord 3 Cmaj", sets)
+	}
+	issues := c.LastReport().Issues
+	if len(issues) == 0 || issues[0].Kind != IssueCollision || issues[0].File != "Set/3 Dmin.mid" {
+		t.Errorf("issues = %+v, want a collision for Set/3 Dmin.mid", issues)
+	}
+}
+
+func TestMidiExtensions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/1 Cmaj.mid":  smfFile(smfChord(t, 60, 64, 67)),
+		"Set/2 Dmin.MIDI": smfFile(smfChord(t, 62, 65, 69)),
+		"Set/3 Emin.midi": smfFile(smfChord(t, 64, 67, 71)),
+		"Set/4 Fmaj.smf":  smfFile(smfChord(t, 65, 69, 72)),
+	}
+
+	c := testConverter()
+	c.SetMaxChords(4)
+	sets := convertFS(t, &c, fsys)
+	if got, want := chordNames(sets[0].Chords), []string{"Cmaj", "Dmin", "Emin", "Chd 4"}; !slices.Equal(got, want) {
+		t.Errorf("default extensions: chords = %q, want %q", got, want)
+	}
+
+	c.SetMidiExtensions(".smf")
+	sets = convertFS(t, &c, fsys)
+	if got, want := chordNames(sets[0].Chords), []string{"Chd 1", "Chd 2", "Chd 3", "Fmaj"}; !slices.Equal(got, want) {
+		t.Errorf("custom extensions: chords = %q, want %q", got, want)
+	}
+}
+
+func TestParseChordFileNameCaseInsensitiveExtension(t *testing.T) {
+	for _, fileName := range []string{"1 Cmaj.mid", "1 Cmaj.MID", "1 Cmaj.Mid"} {
+		number, name, err := parseChordFileName(re, fileName)
+		if err != nil {
+			t.Errorf("parseChordFileName(%q) error = %v", fileName, err)
+			continue
+		}
+		if number != 1 || name != "Cmaj" {
+			t.Errorf("parseChordFileName(%q) = %d, %q, want 1, \"Cmaj\"", fileName, number, name)
+		}
+	}
+}
+
+func TestSetOrder(t *testing.T) {
+	now := time.Now()
+	fsys := fstest.MapFS{
+		"Beta":          {Mode: fs.ModeDir | 0755, ModTime: now.Add(2 * time.Hour)},
+		"Alpha":         {Mode: fs.ModeDir | 0755, ModTime: now.Add(time.Hour)},
+		"Gamma":         {Mode: fs.ModeDir | 0755, ModTime: now.Add(-time.Hour)},
+		"Beta/1 C.mid":  smfFile(smfChord(t, 60, 64, 67)),
+		"Alpha/1 C.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"Gamma/1 C.mid": smfFile(smfChord(t, 60, 64, 67)),
+	}
+
+	for _, tt := range []struct {
+		order SetOrder
+		want  []string
+	}{
+		{SetOrderNameAsc, []string{"Alpha", "Beta", "Gamma"}},
+		{SetOrderNameDesc, []string{"Gamma", "Beta", "Alpha"}},
+		{SetOrderModTime, []string{"Gamma", "Alpha", "Beta"}},
+	} {
+		t.Run(tt.order.String(), func(t *testing.T) {
+			c := testConverter()
+			c.SetSetOrder(tt.order)
+			sets := convertFS(t, &c, fsys)
+
+			if got := setNames(sets); !slices.Equal(got, tt.want) {
+				t.Errorf("sets = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameSource(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/1 Named.mid": smfFile(smfNamedChord(t, "Cmaj7", 60, 64, 67, 71)),
+		"Set/2 Plain.mid": smfFile(smfChord(t, 62, 65, 69)),
+		"Set/3 .mid":      smfFile(smfNamedChord(t, "E minor", 64, 67, 71)),
+	}
+
+	for _, tt := range []struct {
+		source NameSource
+		want   []string
+	}{
+		{NameSourceFilename, []string{"Named", "Plain", "Emin"}},
+		{NameSourceMetaText, []string{"Cmaj7", "Plain", "E minor"}},
+		{NameSourceFilenameThenMeta, []string{"Named", "Plain", "E minor"}},
+	} {
+		t.Run(tt.source.String(), func(t *testing.T) {
+			c := testConverter()
+			c.SetMaxChords(3)
+			c.SetNameSource(tt.source)
+			sets := convertFS(t, &c, fsys)
+
+			if got := chordNames(sets[0].Chords); !slices.Equal(got, tt.want) {
+				t.Errorf("chords = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoteMode(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"Set/2 Amin.mid": smfFile(smfChord(t, 57, 60, 64)),
+	}
+
+	for _, tt := range []struct {
+		mode NoteMode
+		want [][]int
+	}{
+		{NoteModeRelative, [][]int{{0, 4, 7}, {-3, 0, 4}}},
+		{NoteModeAbsolute, [][]int{{60, 64, 67}, {57, 60, 64}}},
+	} {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			c := testConverter()
+			c.SetMaxChords(2)
+			c.SetNoteMode(tt.mode)
+			sets := convertFS(t, &c, fsys)
+
+			for i, want := range tt.want {
+				if got := sets[0].Chords[i].Notes; !slices.Equal(got, want) {
+					t.Errorf("chord %d notes = %v, want %v", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestContinueOnError(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/1 Cmaj.mid":  smfFile(smfChord(t, 60, 64, 67)),
+		"Set/Bad.mid":     smfFile(smfChord(t, 62, 65, 69)),
+		"Set/3 Emin.mid":  smfFile(smfChord(t, 64, 67, 71)),
+		"Set/x Worse.mid": smfFile(smfChord(t, 65, 69, 72)),
+	}
+
+	t.Run("fail-fast", func(t *testing.T) {
+		c := testConverter()
+		sets, err := c.ConvertFolder(fsys, ".")
+		if err == nil || !strings.Contains(err.Error(), "invalid file name: Bad.mid") {
+			t.Fatalf("ConvertFolder() error = %v, want an invalid file name error", err)
+		}
+		if len(sets) != 0 {
+			t.Errorf("got %d sets, want none", len(sets))
+		}
+	})
+
+	t.Run("continue", func(t *testing.T) {
+		c := testConverter()
+		c.SetContinueOnError(true)
+		sets, err := c.ConvertFolder(fsys, ".")
+		if err == nil || !strings.Contains(err.Error(), "2 files skipped") ||
+			!strings.Contains(err.Error(), "Set/Bad.mid") || !strings.Contains(err.Error(), "Set/x Worse.mid") {
+			t.Fatalf("ConvertFolder() error = %v, want an error listing both skipped files", err)
+		}
+		if len(sets) != 1 {
+			t.Fatalf("got %d sets, want 1", len(sets))
+		}
+		if got := chordNames(sets[0].Chords[:3]); !slices.Equal(got, []string{"Cmaj", "Chd 2", "Emin"}) {
+			t.Errorf("chords = %q, want Cmaj, Chd 2, Emin", got)
+		}
+	})
+}
+
+func TestFileWithoutNotes(t *testing.T) {
+	controllers := smfData(t, func(wr *writer.SMF) error {
+		if err := writer.ControlChange(wr, 64, 127); err != nil {
+			return err
+		}
+		wr.SetDelta(wr.Ticks4th())
+		return writer.Pitchbend(wr, 1000)
+	})
+	fsys := fstest.MapFS{
+		"Set/1 Cmaj.mid":  smfFile(smfChord(t, 60, 64, 67)),
+		"Set/2 Pedal.mid": smfFile(controllers),
+	}
+
+	var log strings.Builder
+	c := testConverter()
+	c.SetLogger(slog.New(slog.NewTextHandler(&log, nil)))
+	sets := convertFS(t, &c, fsys)
+
+	if got := sets[0].Chords[1]; got.Name != "Chd 2" || len(got.Notes) != 0 {
+		t.Errorf("chord 2 = %+v, want an empty chord", got)
+	}
+	if !strings.Contains(log.String(), "no notes found in file") || !strings.Contains(log.String(), "Set/2 Pedal.mid") {
+		t.Errorf("log doesn't warn about the file without notes:\n%s", log.String())
+	}
+	if got := c.LastSummary().FilesSkipped; got != 1 {
+		t.Errorf("FilesSkipped = %d, want 1", got)
+	}
+	want := Issue{Kind: IssueFileSkipped, Set: "Set", File: "Set/2 Pedal.mid", Message: "no notes found in file"}
+	if !slices.Contains(c.LastReport().Issues, want) {
+		t.Errorf("issues = %+v, want %+v", c.LastReport().Issues, want)
+	}
+}
+
+func TestThreeDigitChordNumbers(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/100 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"Set/128 Dmin.mid": smfFile(smfChord(t, 62, 65, 69)),
+		"Set/129 Emin.mid": smfFile(smfChord(t, 64, 67, 71)),
+	}
+
+	c := testConverter()
+	c.SetMaxChords(128)
+	sets := convertFS(t, &c, fsys)
+
+	if got := len(sets[0].Chords); got != 128 {
+		t.Fatalf("got %d chords, want 128", got)
+	}
+	if got := sets[0].Chords[99]; got.Name != "Cmaj" || !slices.Equal(got.Notes, []int{0, 4, 7}) {
+		t.Errorf("chord 100 = %+v, want Cmaj [0 4 7]", got)
+	}
+	if got := sets[0].Chords[127].Name; got != "Dmin" {
+		t.Errorf("chord 128 = %q, want Dmin", got)
+	}
+	if got := c.LastSummary().FilesSkipped; got != 1 {
+		t.Errorf("FilesSkipped = %d, want 1 (chord 129 is out of range)", got)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Piano/1 Cmaj.mid":       smfFile(smfChord(t, 60, 64, 67)),
+		"Piano/Voicings/2 D.mid": smfFile(smfChord(t, 62, 66, 69)),
+		"Pads/Warm/1 Emin.mid":   smfFile(smfChord(t, 64, 67, 71)),
+	}
+
+	for _, tt := range []struct {
+		depth int
+		want  []string
+	}{
+		{1, []string{"Pads", "Piano"}},
+		{2, []string{"Pads", "Piano", "Voicings", "Warm"}},
+	} {
+		c := testConverter()
+		c.SetMaxDepth(tt.depth)
+		sets := convertFS(t, &c, fsys)
+
+		if got := setNames(sets); !slices.Equal(got, tt.want) {
+			t.Errorf("depth %d: sets = %q, want %q", tt.depth, got, tt.want)
+		}
+	}
+}
+
+func TestHiddenAndIgnoredFolders(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Piano/1 Cmaj.mid":        smfFile(smfChord(t, 60, 64, 67)),
+		"Piano/.cache/2 Dmin.mid": smfFile(smfChord(t, 62, 65, 69)),
+		".cache/1 Emin.mid":       smfFile(smfChord(t, 64, 67, 71)),
+		".git/1 Fmaj.mid":         smfFile(smfChord(t, 65, 69, 72)),
+		"Temp1/1 Gmaj.mid":        smfFile(smfChord(t, 67, 71, 74)),
+		".DS_Store":               {Data: []byte{0}},
+	}
+
+	c := testConverter()
+	c.SetIgnorePatterns("Temp*")
+	sets := convertFS(t, &c, fsys)
+
+	if len(sets) != 1 || sets[0].Name != "Piano" {
+		t.Fatalf("sets = %+v, want only Piano", sets)
+	}
+	if got := sets[0].Chords[1].Name; got != "Chd 2" {
+		t.Errorf("chord 2 = %q from a hidden subfolder, want Chd 2", got)
+	}
+}
+
+func TestEmptyChordName(t *testing.T) {
+	fsys := fstest.MapFS{"Set/2 Dmin.mid": smfFile(smfChord(t, 62, 65, 69))}
+
+	for _, tt := range []struct {
+		name string
+		want []string
+	}{
+		{"Chd", []string{"Chd 1", "Dmin", "Chd 3"}},
+		{"Akkord", []string{"Akkord 1", "Dmin", "Akkord 3"}},
+		{"Pad {index} (free)", []string{"Pad 1 (free)", "Dmin", "Pad 3 (free)"}},
+	} {
+		c := testConverter()
+		c.SetMaxChords(3)
+		c.SetEmptyChordName(tt.name)
+		sets := convertFS(t, &c, fsys)
+
+		if got := chordNames(sets[0].Chords); !slices.Equal(got, tt.want) {
+			t.Errorf("SetEmptyChordName(%q): chords = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOmitEmptyChords(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Full/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"Full/3 Emin.mid": smfFile(smfChord(t, 64, 67, 71)),
+		"None/notes.txt":  {Data: []byte("no chords yet")},
+	}
+
+	c := testConverter()
+	c.SetMaxChords(4)
+	sets := convertFS(t, &c, fsys)
+	if got, want := chordNames(sets[0].Chords), []string{"Cmaj", "Chd 2", "Emin", "Chd 4"}; !slices.Equal(got, want) {
+		t.Errorf("without omitting: chords = %q, want %q", got, want)
+	}
+
+	var log strings.Builder
+	c.SetLogger(slog.New(slog.NewTextHandler(&log, nil)))
+	c.SetOmitEmptyChords(true)
+	sets = convertFS(t, &c, fsys)
+	if got, want := chordNames(sets[0].Chords), []string{"Cmaj", "Emin"}; !slices.Equal(got, want) {
+		t.Errorf("omitting: chords = %q, want %q", got, want)
+	}
+	if len(sets[1].Chords) != 0 {
+		t.Errorf("omitting: set None has %d chords, want none", len(sets[1].Chords))
+	}
+	if !strings.Contains(log.String(), "all chords are empty, set has no chords") {
+		t.Errorf("log doesn't warn about the set without chords:\n%s", log.String())
+	}
+}
+
+func TestDeterministicUUID(t *testing.T) {
+	fsys := fixtureTree(t, 2, 12)
+	changed := fixtureTree(t, 2, 12)
+	changed["Set 01/1 Chord 1.mid"] = smfFile(smfChord(t, 60, 63, 67))
+
+	uuids := func(deterministic bool, fsys fstest.MapFS) []string {
+		c := testConverter()
+		c.SetDeterministicUUID(deterministic)
+		var ids []string
+		for _, set := range convertFS(t, &c, fsys) {
+			ids = append(ids, set.UUID)
+		}
+		return ids
+	}
+
+	first, second := uuids(true, fsys), uuids(true, fsys)
+	if !slices.Equal(first, second) {
+		t.Errorf("UUIDs differ between runs: %q and %q", first, second)
+	}
+	if first[0] == first[1] {
+		t.Errorf("sets have the same UUID %s", first[0])
+	}
+	if got := uuids(true, changed); got[0] == first[0] || got[1] != first[1] {
+		t.Errorf("UUIDs after changing set 1 = %q, want a new UUID for set 1 only (was %q)", got, first)
+	}
+	if random := uuids(false, fsys); slices.Equal(random, uuids(false, fsys)) {
+		t.Errorf("random UUIDs are the same across runs: %q", random)
+	}
+}
+
+func TestSetFilter(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for _, name := range []string{"Piano", "PianoTemp", "Pads", "Temp"} {
+		fsys[name+"/1 Cmaj.mid"] = smfFile(smfChord(t, 60, 64, 67))
+	}
+
+	for _, tt := range []struct {
+		name             string
+		include, exclude []string
+		want             []string
+	}{
+		{"none", nil, nil, []string{"Pads", "Piano", "PianoTemp", "Temp"}},
+		{"include", []string{"Piano*"}, nil, []string{"Piano", "PianoTemp"}},
+		{"exclude", nil, []string{"*Temp"}, []string{"Pads", "Piano"}},
+		{"both", []string{"Piano*", "Temp"}, []string{"*Temp"}, []string{"Piano"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testConverter()
+			c.SetSetFilter(tt.include, tt.exclude)
+			sets := convertFS(t, &c, fsys)
+
+			if got := setNames(sets); !slices.Equal(got, tt.want) {
+				t.Errorf("sets = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxSetFolderNameLen(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Jazz/1 Cmaj.mid":           smfFile(smfChord(t, 60, 64, 67)),
+		"Jazz Chords II/1 Dmin.mid": smfFile(smfChord(t, 62, 65, 69)),
+	}
+
+	for _, tt := range []struct {
+		maxLen int
+		want   []string
+	}{
+		{maxSetFolderNameLen, []string{"Jazz"}},
+		{14, []string{"Jazz", "Jazz Chords II"}},
+		{0, []string{"Jazz", "Jazz Chords II"}},
+	} {
+		var log strings.Builder
+		c := testConverter()
+		c.SetLogger(slog.New(slog.NewTextHandler(&log, &slog.HandlerOptions{Level: slog.LevelDebug})))
+		c.SetMaxSetFolderNameLen(tt.maxLen)
+		sets := convertFS(t, &c, fsys)
+
+		if got := setNames(sets); !slices.Equal(got, tt.want) {
+			t.Errorf("max length %d: sets = %q, want %q", tt.maxLen, got, tt.want)
+		}
+		if skipped := strings.Contains(log.String(), "folder name is too long"); skipped != (len(tt.want) == 1) {
+			t.Errorf("max length %d: skipped folder logged = %v:\n%s", tt.maxLen, skipped, log.String())
+		}
+	}
+}
+
+func TestMaxNotesPerChord(t *testing.T) {
+	fsys := fstest.MapFS{
+		// a dense 10-note cluster, played from the top down
+		"Set/1 Cluster.mid": smfFile(smfChord(t, 69, 68, 67, 66, 65, 64, 63, 62, 61, 60)),
+	}
+
+	for _, tt := range []struct {
+		policy  NoteLimitPolicy
+		want    []int
+		wantErr bool
+	}{
+		{NoteLimitTruncate, []int{0, 1, 2, 3, 4, 5}, false},
+		{NoteLimitWarn, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, false},
+		{NoteLimitError, nil, true},
+	} {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			c := testConverter()
+			c.SetMaxNotesPerChord(6, tt.policy)
+			sets, err := c.ConvertFolder(fsys, ".")
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "too many notes") {
+					t.Errorf("ConvertFolder() error = %v, want a too many notes error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ConvertFolder() error = %v", err)
+			}
+			if got := sets[0].Chords[0].Notes; !slices.Equal(got, tt.want) {
+				t.Errorf("notes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgressFunc(t *testing.T) {
+	fsys := fixtureTree(t, 5, 12)
+
+	var calls, total int
+	var dones []int
+	seen := make(map[string]bool)
+	c := testConverter()
+	c.SetProgressFunc(func(done, n int, currentSet string) {
+		calls++
+		total = n
+		dones = append(dones, done)
+		seen[currentSet] = true
+	})
+	convertFS(t, &c, fsys)
+
+	if calls != 5 || total != 5 {
+		t.Errorf("progress called %d times with total %d, want 5 and 5", calls, total)
+	}
+	if !slices.Equal(dones, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("done values = %v, want 1..5", dones)
+	}
+	if len(seen) != 5 || !seen["Set 03"] {
+		t.Errorf("reported sets = %v, want all 5 sets", seen)
+	}
+}
+
+func TestSplitChordComment(t *testing.T) {
+	tests := []struct {
+		name, wantName, wantComment string
+	}{
+		{"Cmaj7 (bright)", "Cmaj7", "bright"},
+		{"Cmaj7(bright)", "Cmaj7", "bright"},
+		{"Cmaj7 ( wide voicing )", "Cmaj7", "wide voicing"},
+		{"Cmaj7", "Cmaj7", ""},
+		{"Cmaj7 (b5) lift", "Cmaj7 (b5) lift", ""},
+	}
+	for _, tt := range tests {
+		if name, comment := splitChordComment(tt.name); name != tt.wantName || comment != tt.wantComment {
+			t.Errorf("splitChordComment(%q) = %q, %q, want %q, %q", tt.name, name, comment, tt.wantName, tt.wantComment)
+		}
+	}
+}
+
+func TestSplitComments(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/1 Cmaj7 (bright).mid": smfFile(smfChord(t, 60, 64, 67, 71)),
+		"Set/2 Dmin.mid":           smfFile(smfChord(t, 62, 65, 69)),
+	}
+
+	c := testConverter()
+	c.SetMaxChords(2)
+	sets := convertFS(t, &c, fsys)
+	if got := sets[0].Chords[0]; got.Name != "Cmaj7 (bright)" || got.Comment != "" {
+		t.Errorf("without splitting: chord 1 = %q, %q, want the full name and no comment", got.Name, got.Comment)
+	}
+
+	c.SetSplitComments(true)
+	sets = convertFS(t, &c, fsys)
+	for i, want := range []Chord{{Name: "Cmaj7", Comment: "bright"}, {Name: "Dmin"}} {
+		if got := sets[0].Chords[i]; got.Name != want.Name || got.Comment != want.Comment {
+			t.Errorf("chord %d = %q, %q, want %q, %q", i+1, got.Name, got.Comment, want.Name, want.Comment)
+		}
+	}
+}
+
+func TestDuplicateSetNames(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Cmin/1 Cmin.mid":   smfFile(smfChord(t, 60, 63, 67)),
+		"Cmin /1 Cmin.mid":  smfFile(smfChord(t, 60, 63, 67)),
+		" Pads  Hi/1 C.mid": smfFile(smfChord(t, 60, 64, 67)),
+	}
+
+	c := testConverter()
+	sets := convertFS(t, &c, fsys)
+
+	if got, want := setNames(sets), []string{"Pads Hi", "Cmin", "Cmin"}; !slices.Equal(got, want) {
+		t.Errorf("sets = %q, want %q", got, want)
+	}
+	want := Issue{Kind: IssueCollision, Set: "Cmin", File: "Cmin ", Message: "set folder has the same name as Cmin"}
+	if issues := c.LastReport().Issues; !slices.Contains(issues, want) {
+		t.Errorf("issues = %+v, want %+v", issues, want)
+	}
+}
+
+func TestSetsFolderName(t *testing.T) {
+	fsys := fstest.MapFS{
+		"chords/Piano/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"chords/Pads/2 Dmin.mid":  smfFile(smfChord(t, 62, 65, 69)),
+	}
+
+	c := testConverter()
+	c.SetSetsFolderName("chords")
+	sets := convertFS(t, &c, fsys)
+	if got, want := setNames(sets), []string{"Pads", "Piano"}; !slices.Equal(got, want) {
+		t.Errorf("custom sets folder name: sets = %q, want %q", got, want)
+	}
+
+	c.SetSetsFolderName(setsFolderName)
+	sets = convertFS(t, &c, fsys)
+	if got, want := setNames(sets), []string{"chords"}; !slices.Equal(got, want) {
+		t.Errorf("default sets folder name: sets = %q, want %q", got, want)
+	}
+}
+
+func TestIncludeSubfolders(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Piano/1 Cmaj.mid":           smfFile(smfChord(t, 60, 64, 67)),
+		"Piano/Jazz/2 Dmin7.mid":     smfFile(smfChord(t, 62, 65, 69, 72)),
+		"Piano/Jazz/Rootless/3 .mid": smfFile(smfChord(t, 64, 67, 71)),
+	}
+
+	for _, tt := range []struct {
+		include bool
+		want    []string
+	}{
+		{true, []string{"Cmaj", "Dmin7", "Emin"}},
+		{false, []string{"Cmaj", "Chd 2", "Chd 3"}},
+	} {
+		c := testConverter()
+		c.SetMaxChords(3)
+		c.SetIncludeSubfolders(tt.include)
+		sets := convertFS(t, &c, fsys)
+
+		if got := setNames(sets); !slices.Equal(got, []string{"Piano"}) {
+			t.Errorf("include %v: sets = %q, want only Piano", tt.include, got)
+		}
+		if got := chordNames(sets[0].Chords); !slices.Equal(got, tt.want) {
+			t.Errorf("include %v: chords = %q, want %q", tt.include, got, tt.want)
+		}
+	}
+
+	fsys["Piano/Jazz/1 Cmaj7.mid"] = smfFile(smfChord(t, 60, 64, 67, 71))
+	c := testConverter()
+	if _, err := c.ConvertFolder(fsys, "."); !errors.Is(err, ErrDuplicateNumber) {
+		t.Errorf("chord number 1 in a subfolder: error = %v, want %v", err, ErrDuplicateNumber)
+	}
+}
+
+func TestRequireContiguous(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"Set/2 Dmin.mid": smfFile(smfChord(t, 62, 65, 69)),
+		"Set/4 Fmaj.mid": smfFile(smfChord(t, 65, 69, 72)),
+	}
+
+	c := testConverter()
+	c.SetMaxChords(4)
+	c.SetRequireContiguous(true)
+	_, err := c.ConvertFolder(fsys, ".")
+	if !errors.Is(err, ErrMissingChords) || !strings.HasSuffix(err.Error(), ": 3") {
+		t.Errorf("ConvertFolder() error = %v, want %v reporting chord 3", err, ErrMissingChords)
+	}
+
+	var log strings.Builder
+	c.SetLogger(slog.New(slog.NewTextHandler(&log, nil)))
+	c.SetContinueOnError(true)
+	sets := convertFS(t, &c, fsys)
+	if len(sets) != 1 {
+		t.Fatalf("continue on error: got %d sets, want 1", len(sets))
+	}
+	if !strings.Contains(log.String(), "missing chord numbers") || !strings.Contains(log.String(), "numbers=3") {
+		t.Errorf("continue on error: log doesn't report chord 3:\n%s", log.String())
+	}
+
+	c = testConverter()
+	c.SetMaxChords(2)
+	c.SetRequireContiguous(true)
+	convertFS(t, &c, fsys) // chords 1 and 2 are complete, chord 4 is out of range
+}
+
+func TestParseChordFileNameSpaces(t *testing.T) {
+	tests := []struct {
+		fileName string
+		number   int
+		name     string
+	}{
+		{"3 Cmaj.mid", 3, "Cmaj"},
+		{"3  Cmaj.mid", 3, "Cmaj"},
+		{"3 \t Cmaj7 .mid", 3, "Cmaj7"},
+		{"3\tCmaj.mid", 3, "Cmaj"},
+		{"12 A min 9.mid", 12, "A min 9"},
+	}
+	for _, tt := range tests {
+		number, name, err := parseChordFileName(re, tt.fileName)
+		if err != nil || number != tt.number || name != tt.name {
+			t.Errorf("parseChordFileName(%q) = %d, %q, %v, want %d, %q", tt.fileName, number, name, err, tt.number, tt.name)
+		}
+	}
+
+	for _, fileName := range []string{"Cmaj.mid", "3Cmaj.mid", "3_Cmaj.mid", "1234 Cmaj.mid"} {
+		if _, _, err := parseChordFileName(re, fileName); err == nil {
+			t.Errorf("parseChordFileName(%q) error = nil, want an error", fileName)
+		}
+	}
+}
+
+func TestFileNameSeparators(t *testing.T) {
+	for _, tt := range []struct {
+		separators string
+		fileName   string
+		valid      bool
+	}{
+		{"", "03 Cmaj.mid", true},
+		{"", "03_Cmaj.mid", false},
+		{"_", "03_Cmaj.mid", true},
+		{"_", "03-Cmaj.mid", false},
+		{"-", "03-Cmaj.mid", true},
+		{"_-", "03 - Cmaj.mid", true},
+		{"_-", "03__Cmaj.mid", true},
+		{"_-", "03 Cmaj.mid", true},
+	} {
+		number, name, err := parseChordFileName(fileNameRegexp(tt.separators), tt.fileName)
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("separators %q: parseChordFileName(%q) error = nil, want an error", tt.separators, tt.fileName)
+			}
+			continue
+		}
+		if err != nil || number != 3 || name != "Cmaj" {
+			t.Errorf("separators %q: parseChordFileName(%q) = %d, %q, %v, want 3, \"Cmaj\"",
+				tt.separators, tt.fileName, number, name, err)
+		}
+	}
+
+	c := testConverter()
+	c.SetFileNameSeparators("_/")
+	if err := c.validate(); err == nil {
+		t.Error("validate() error = nil, want an error for an unsupported separator")
+	}
+}
+
+func TestStrictSetCount(t *testing.T) {
+	fsys := fixtureTree(t, 17, 1)
+
+	c := testConverter()
+	sets := convertFS(t, &c, fsys)
+	if len(sets) != defaultMaxSets || sets[15].Name != "Set 16" {
+		t.Errorf("got %d sets ending with %s, want the first 16", len(sets), sets[len(sets)-1].Name)
+	}
+	want := Issue{Kind: IssueSetSkipped, Set: "Set 17", File: "Set 17", Message: "maximum number of sets reached"}
+	if issues := c.LastReport().Issues; !slices.Contains(issues, want) {
+		t.Errorf("issues = %+v, want %+v", issues, want)
+	}
+
+	c.SetStrictSetCount(true)
+	_, err := c.ConvertFolder(fsys, ".")
+	if !errors.Is(err, ErrTooManySets) || !strings.Contains(err.Error(), "found 17, the maximum is 16") ||
+		!strings.HasSuffix(err.Error(), ": Set 17") {
+		t.Errorf("ConvertFolder() error = %v, want %v with the count and Set 17", err, ErrTooManySets)
+	}
+}
+
+func TestTrackSource(t *testing.T) {
+	fsys := fstest.MapFS{
+		"library/Piano/1 Cmaj.mid":       smfFile(smfChord(t, 60, 64, 67)),
+		"library/Piano/Jazz/2 Dmin7.mid": smfFile(smfChord(t, 62, 65, 69, 72)),
+	}
+
+	c := testConverter()
+	c.SetMaxChords(3)
+	sets, err := c.ConvertFolder(fsys, "library")
+	if err != nil {
+		t.Fatalf("ConvertFolder() error = %v", err)
+	}
+	for _, chord := range sets[0].Chords {
+		if chord.SourceFile != "" {
+			t.Errorf("chord %s has source file %q without tracking", chord.Name, chord.SourceFile)
+		}
+	}
+
+	c.SetTrackSource(true)
+	sets, err = c.ConvertFolder(fsys, "library")
+	if err != nil {
+		t.Fatalf("ConvertFolder() error = %v", err)
+	}
+	for i, want := range []string{"Piano/1 Cmaj.mid", "Piano/Jazz/2 Dmin7.mid", ""} {
+		if got := sets[0].Chords[i].SourceFile; got != want {
+			t.Errorf("chord %d source file = %q, want %q", i+1, got, want)
+		}
+	}
+}
+
+func TestTransform(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Piano/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"Pads/1 Dmin.mid":  smfFile(smfChord(t, 62, 65, 69)),
+		"Temp/1 Emin.mid":  smfFile(smfChord(t, 64, 67, 71)),
+	}
+
+	c := testConverter()
+	c.SetMaxChords(2)
+	c.SetTransform(func(set ChordSet) (ChordSet, error) {
+		if set.Name == "Temp" {
+			return set, ErrSkipSet
+		}
+		for i := range set.Chords {
+			set.Chords[i].Name = strings.ToUpper(set.Chords[i].Name)
+		}
+		return set, nil
+	})
+	sets := convertFS(t, &c, fsys)
+
+	if got := setNames(sets); !slices.Equal(got, []string{"Pads", "Piano"}) {
+		t.Errorf("sets = %q, want Pads and Piano", got)
+	}
+	if got, want := chordNames(sets[1].Chords), []string{"CMAJ", "CHD 2"}; !slices.Equal(got, want) {
+		t.Errorf("chords = %q, want %q", got, want)
+	}
+
+	failure := errors.New("bad set")
+	c.SetTransform(func(set ChordSet) (ChordSet, error) { return set, failure })
+	if _, err := c.ConvertFolder(fsys, "."); !errors.Is(err, failure) {
+		t.Errorf("ConvertFolder() error = %v, want the transform error", err)
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	fsys := fstest.MapFS{"Set/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67))}
+	clock := func() time.Time { return time.Date(2024, 3, 5, 14, 30, 0, 0, time.FixedZone("CET", 3600)) }
+
+	for _, tt := range []struct {
+		name      string
+		timestamp bool
+		want      string
+	}{
+		{"disabled", false, ""},
+		{"enabled", true, "2024-03-05T13:30:00Z"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testConverter()
+			c.SetClock(clock)
+			c.SetTimestamp(tt.timestamp)
+			sets := convertFS(t, &c, fsys)
+			if got := sets[0].GeneratedAt; got != tt.want {
+				t.Errorf("GeneratedAt = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChordNumberBase(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for n := range 12 {
+		fsys[fmt.Sprintf("Set/%d Chord %d.mid", n, n)] = smfFile(smfChord(t, uint8(60+n)))
+	}
+	fsys["Set/12 Extra.mid"] = smfFile(smfChord(t, 72))
+
+	c := testConverter()
+	c.SetChordNumberBase(0)
+	sets := convertFS(t, &c, fsys)
+
+	chords := sets[0].Chords
+	if len(chords) != 12 {
+		t.Fatalf("got %d chords, want 12", len(chords))
+	}
+	for n, chord := range chords {
+		if want := fmt.Sprintf("Chord %d", n); chord.Name != want || !slices.Equal(chord.Notes, []int{n}) {
+			t.Errorf("chord %d = %+v, want %s [%d]", n+1, chord, want, n)
+		}
+	}
+	issues := c.LastReport().Issues
+	if want := "chord number 12 is out of range 0..11"; len(issues) != 1 || issues[0].Message != want {
+		t.Errorf("issues = %+v, want one with message %q", issues, want)
+	}
+}
+
+func TestChordNumberBaseInvalid(t *testing.T) {
+	fsys := fstest.MapFS{"Set/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67))}
+
+	for _, base := range []int{-1, 990} {
+		c := testConverter()
+		c.SetChordNumberBase(base)
+		if _, err := c.ConvertFolder(fsys, "."); err == nil {
+			t.Errorf("ConvertFolder() with chord number base %d: want an error", base)
+		}
+	}
+}
+
+func TestNumberingAutoSequential(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/Gmaj.mid":   smfFile(smfChord(t, 67, 71, 74)),
+		"Set/2 Dmin.mid": smfFile(smfChord(t, 62, 65, 69)),
+		"Set/Amin.mid":   smfFile(smfChord(t, 69, 72, 76)),
+		"Set/Cmaj.mid":   smfFile(smfChord(t, 60, 64, 67)),
+	}
+
+	c := testConverter()
+	c.SetMaxChords(4)
+	c.SetNumberingMode(NumberingAutoSequential)
+	sets := convertFS(t, &c, fsys)
+
+	// the numbered file keeps its slot, the others fill the free slots in name order
+	if got, want := chordNames(sets[0].Chords), []string{"Amin", "Dmin", "Cmaj", "Gmaj"}; !slices.Equal(got, want) {
+		t.Errorf("chords = %q, want %q", got, want)
+	}
+
+	c = testConverter()
+	c.SetMaxChords(4)
+	if _, err := c.ConvertFolder(fsys, "."); err == nil {
+		t.Error("ConvertFolder() of files without numbers in filename numbering mode: want an error")
+	}
+}
+
+func TestNumberingAutoSequentialNoFreeSlot(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"Set/2 Dmin.mid": smfFile(smfChord(t, 62, 65, 69)),
+		"Set/Emin.mid":   smfFile(smfChord(t, 64, 67, 71)),
+	}
+
+	c := testConverter()
+	c.SetMaxChords(2)
+	c.SetNumberingMode(NumberingAutoSequential)
+	sets := convertFS(t, &c, fsys)
+
+	if got, want := chordNames(sets[0].Chords), []string{"Cmaj", "Dmin"}; !slices.Equal(got, want) {
+		t.Errorf("chords = %q, want %q", got, want)
+	}
+	if issues := c.LastReport().Issues; len(issues) != 1 || issues[0].File != "Set/Emin.mid" {
+		t.Errorf("issues = %+v, want Set/Emin.mid skipped", issues)
+	}
+}
+
+func TestDedupeSets(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Alpha/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"Alpha/2 Dmin.mid": smfFile(smfChord(t, 62, 65, 69)),
+		"Beta/1 Cmaj.mid":  smfFile(smfChord(t, 60, 64, 67)),
+		"Beta/2 Dmin.mid":  smfFile(smfChord(t, 62, 65, 69)),
+		"Gamma/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"Gamma/2 Dmin.mid": smfFile(smfChord(t, 62, 65, 70)),
+	}
+
+	for _, tt := range []struct {
+		dedupe bool
+		want   []string
+	}{
+		{false, []string{"Alpha", "Beta", "Gamma"}},
+		{true, []string{"Alpha", "Gamma"}},
+	} {
+		var log strings.Builder
+		c := testConverter()
+		c.SetLogger(slog.New(slog.NewTextHandler(&log, nil)))
+		c.SetMaxChords(2)
+		c.SetDedupeSets(tt.dedupe)
+		sets := convertFS(t, &c, fsys)
+
+		if got := setNames(sets); !slices.Equal(got, tt.want) {
+			t.Errorf("dedupe %v: sets = %q, want %q", tt.dedupe, got, tt.want)
+		}
+		const skipped = `msg="duplicate set skipped" name=Beta duplicate_of=Alpha`
+		if logged := strings.Contains(log.String(), skipped); logged != tt.dedupe {
+			t.Errorf("dedupe %v: duplicate logged = %v:\n%s", tt.dedupe, logged, log.String())
+		}
+		if strings.Count(log.String(), "duplicate set skipped") > 1 {
+			t.Errorf("dedupe %v: more than one duplicate logged:\n%s", tt.dedupe, log.String())
+		}
+	}
+}
+
+func TestChordsEqual(t *testing.T) {
+	base := []Chord{{Name: "Cmaj", Notes: []int{0, 4, 7}}, {Name: "Chd 2", Notes: []int{}}}
+
+	for _, tt := range []struct {
+		name string
+		b    []Chord
+		want bool
+	}{
+		{"equal", []Chord{{Name: "Cmaj", Notes: []int{0, 4, 7}}, {Name: "Chd 2", Notes: []int{}}}, true},
+		{"nil notes", []Chord{{Name: "Cmaj", Notes: []int{0, 4, 7}}, {Name: "Chd 2"}}, true},
+		{"note", []Chord{{Name: "Cmaj", Notes: []int{0, 3, 7}}, {Name: "Chd 2", Notes: []int{}}}, false},
+		{"name", []Chord{{Name: "C", Notes: []int{0, 4, 7}}, {Name: "Chd 2", Notes: []int{}}}, false},
+		{"comment", []Chord{{Name: "Cmaj", Notes: []int{0, 4, 7}, Comment: "x"}, {Name: "Chd 2", Notes: []int{}}}, false},
+		{"length", base[:1], false},
+	} {
+		if got := chordsEqual(base, tt.b); got != tt.want {
+			t.Errorf("%s: chordsEqual() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package converter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ChordDiff describes the differences of a single chord slot between two chord sets.
+type ChordDiff struct {
+	Number       int    // chord number (starting from 1)
+	OldName      string // chord name in the old set (empty if the slot is missing)
+	NewName      string // chord name in the new set (empty if the slot is missing)
+	AddedNotes   []int  // notes present only in the new chord
+	RemovedNotes []int  // notes present only in the old chord
+}
+
+// ChordSetDiff describes the differences between two chord sets.
+type ChordSetDiff struct {
+	OldName string      // name of the old set
+	NewName string      // name of the new set
+	Chords  []ChordDiff // chord slots that differ, in slot order
+}
+
+// DiffChordSets compares two chord sets slot by slot and returns the chord slots whose names or notes differ.
+// Slots present in only one of the sets are compared against an empty chord.
+func DiffChordSets(a, b ChordSet) ChordSetDiff {
+	diff := ChordSetDiff{OldName: a.Name, NewName: b.Name}
+
+	for i := range max(len(a.Chords), len(b.Chords)) {
+		var oldChord, newChord Chord
+		if i < len(a.Chords) {
+			oldChord = a.Chords[i]
+		}
+		if i < len(b.Chords) {
+			newChord = b.Chords[i]
+		}
+
+		cd := ChordDiff{
+			Number:       i + 1,
+			OldName:      oldChord.Name,
+			NewName:      newChord.Name,
+			AddedNotes:   notesDifference(newChord.Notes, oldChord.Notes),
+			RemovedNotes: notesDifference(oldChord.Notes, newChord.Notes),
+		}
+
+		if cd.OldName != cd.NewName || len(cd.AddedNotes) > 0 || len(cd.RemovedNotes) > 0 {
+			diff.Chords = append(diff.Chords, cd)
+		}
+	}
+
+	return diff
+}
+
+// notesDifference returns the sorted notes of a that are not present in b.
+func notesDifference(a, b []int) []int {
+	var diff []int
+	for _, note := range a {
+		if !slices.Contains(b, note) && !slices.Contains(diff, note) {
+			diff = append(diff, note)
+		}
+	}
+	slices.Sort(diff)
+
+	return diff
+}
+
+// Empty reports whether the chord sets have no chord differences.
+func (d ChordSetDiff) Empty() bool {
+	return len(d.Chords) == 0
+}
+
+// String returns a human-readable representation of the differences, one line per changed chord slot.
+func (d ChordSetDiff) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "diff %s -> %s: ", d.OldName, d.NewName)
+	if d.Empty() {
+		sb.WriteString("no differences")
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "%d chords changed", len(d.Chords))
+
+	for _, cd := range d.Chords {
+		fmt.Fprintf(&sb, "\n  chord %d:", cd.Number)
+		if cd.OldName != cd.NewName {
+			fmt.Fprintf(&sb, " name %q -> %q", cd.OldName, cd.NewName)
+		}
+		if len(cd.AddedNotes) > 0 {
+			fmt.Fprintf(&sb, " added notes %v", cd.AddedNotes)
+		}
+		if len(cd.RemovedNotes) > 0 {
+			fmt.Fprintf(&sb, " removed notes %v", cd.RemovedNotes)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,89 @@
+package converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffChordSets(t *testing.T) {
+	old := ChordSet{Name: "Old", Chords: []Chord{
+		{Name: "Cmaj", Notes: []int{0, 4, 7}},
+		{Name: "Dmin", Notes: []int{2, 5, 9}},
+		{Name: "Emin", Notes: []int{4, 7, 11}},
+	}}
+
+	for _, tt := range []struct {
+		name   string
+		chords []Chord
+		want   []ChordDiff
+	}{
+		{"equal", old.Chords, nil},
+		{
+			"added and removed notes",
+			[]Chord{{Name: "Cmaj", Notes: []int{0, 4, 7, 11}}, {Name: "Dmin", Notes: []int{2, 9}}, {Name: "Emin", Notes: []int{4, 7, 11}}},
+			[]ChordDiff{
+				{Number: 1, OldName: "Cmaj", NewName: "Cmaj", AddedNotes: []int{11}},
+				{Number: 2, OldName: "Dmin", NewName: "Dmin", RemovedNotes: []int{5}},
+			},
+		},
+		{
+			"changed note",
+			[]Chord{{Name: "Cmaj", Notes: []int{0, 4, 7}}, {Name: "Dmin", Notes: []int{2, 5, 9}}, {Name: "Emin", Notes: []int{4, 8, 11}}},
+			[]ChordDiff{{Number: 3, OldName: "Emin", NewName: "Emin", AddedNotes: []int{8}, RemovedNotes: []int{7}}},
+		},
+		{
+			"renamed chord",
+			[]Chord{{Name: "C", Notes: []int{0, 4, 7}}, {Name: "Dmin", Notes: []int{2, 5, 9}}, {Name: "Emin", Notes: []int{4, 7, 11}}},
+			[]ChordDiff{{Number: 1, OldName: "Cmaj", NewName: "C"}},
+		},
+		{
+			"reordered notes",
+			[]Chord{{Name: "Cmaj", Notes: []int{7, 0, 4}}, {Name: "Dmin", Notes: []int{2, 5, 9}}, {Name: "Emin", Notes: []int{4, 7, 11}}},
+			nil,
+		},
+		{
+			"shorter set",
+			[]Chord{{Name: "Cmaj", Notes: []int{0, 4, 7}}},
+			[]ChordDiff{
+				{Number: 2, OldName: "Dmin", RemovedNotes: []int{2, 5, 9}},
+				{Number: 3, OldName: "Emin", RemovedNotes: []int{4, 7, 11}},
+			},
+		},
+		{
+			"longer set",
+			append(old.Chords[:3:3], Chord{Name: "Fmaj", Notes: []int{5, 9, 12}}),
+			[]ChordDiff{{Number: 4, NewName: "Fmaj", AddedNotes: []int{5, 9, 12}}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffChordSets(old, ChordSet{Name: "New", Chords: tt.chords})
+
+			if diff.OldName != "Old" || diff.NewName != "New" {
+				t.Errorf("set names = %q, %q, want Old, New", diff.OldName, diff.NewName)
+			}
+			if !reflect.DeepEqual(diff.Chords, tt.want) {
+				t.Errorf("DiffChordSets() chords = %+v, want %+v", diff.Chords, tt.want)
+			}
+			if diff.Empty() != (tt.want == nil) {
+				t.Errorf("Empty() = %v, want %v", diff.Empty(), tt.want == nil)
+			}
+		})
+	}
+}
+
+func TestChordSetDiffString(t *testing.T) {
+	diff := ChordSetDiff{OldName: "Old", NewName: "New", Chords: []ChordDiff{
+		{Number: 1, OldName: "Cmaj", NewName: "C"},
+		{Number: 3, OldName: "Emin", NewName: "Emin", AddedNotes: []int{8}, RemovedNotes: []int{7}},
+	}}
+	want := "diff Old -> New: 2 chords changed\n" +
+		"  chord 1: name \"Cmaj\" -> \"C\"\n" +
+		"  chord 3: added notes [8] removed notes [7]"
+	if got := diff.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got, want := (ChordSetDiff{OldName: "A", NewName: "A"}).String(), "diff A -> A: no differences"; got != want {
+		t.Errorf("String() of an empty diff = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+package converter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gitlab.com/gomidi/midi/writer"
+
+	"maschine_chords_converter/internal/helpers"
+)
+
+// ExportChordSetToMIDI writes each non-empty chord of the chord set to a MIDI file in outDir.
+// Files are named "<number> <chord name>.mid" with the chord name made safe for file names (see helpers.SanitizeName),
+// e.g. "3 C_E.mid" for the chord "C/E". The number is the chord number of the converted file, as in ExportCSV,
+// so that sets with omitted empty chords or another chord number base convert back to the same slots. Each file contains the chord notes (offset by baseNote) played simultaneously
+// for one bar. The unchanged chord name is stored as the track name.
+func ExportChordSetToMIDI(set ChordSet, outDir string, baseNote int) error {
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("error creating output folder %s: %w", outDir, err)
+	}
+
+	for i, chord := range set.Chords {
+		if len(chord.Notes) == 0 {
+			continue
+		}
+
+		keys := make([]uint8, 0, len(chord.Notes))
+		for _, note := range chord.Notes {
+			key := note + baseNote
+			if key < minMidiNote || key > maxMidiNote {
+				return fmt.Errorf("note %d of chord %s is out of MIDI range with base note %d", note, chord.Name, baseNote)
+			}
+			keys = append(keys, uint8(key))
+		}
+
+		outFile, err := containedPath(outDir, fmt.Sprintf("%d %s.mid", chordNumber(set, i), helpers.SanitizeName(chord.Name)))
+		if err != nil {
+			return err
+		}
+		if err := writer.WriteSMF(outFile, 1, func(wr *writer.SMF) error {
+			return writeChord(wr, chord.Name, keys)
+		}); err != nil {
+			return fmt.Errorf("error writing MIDI file %s: %w", outFile, err)
+		}
+	}
+
+	return nil
+}
+
+// writeChord writes the track name and the chord keys played simultaneously for one bar (in 4/4).
+func writeChord(wr *writer.SMF, name string, keys []uint8) error {
+	if err := writer.TrackSequenceName(wr, name); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := writer.NoteOn(wr, key, 100); err != nil {
+			return err
+		}
+	}
+
+	wr.SetDelta(wr.Ticks4th() * 4)
+	for _, key := range keys {
+		if err := writer.NoteOff(wr, key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExportCSV writes the chord sets to w as CSV with a header row and a row per chord. The columns are the set name,
+// the chord number, the chord name and the comma-separated notes. The chord number is the number of the chord
+// in the MIDI file names for converted sets (also if empty chords are omitted), otherwise its position in the set.
+func ExportCSV(sets []ChordSet, w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"set", "number", "chord", "notes"}); err != nil {
+		return fmt.Errorf("error writing CSV: %w", err)
+	}
+
+	for _, set := range sets {
+		for i, chord := range set.Chords {
+			notes := make([]string, 0, len(chord.Notes))
+			for _, note := range chord.Notes {
+				notes = append(notes, strconv.Itoa(note))
+			}
+
+			record := []string{set.Name, strconv.Itoa(chordNumber(set, i)), chord.Name, strings.Join(notes, ",")}
+			if err := cw.Write(record); err != nil {
+				return fmt.Errorf("error writing CSV: %w", err)
+			}
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("error writing CSV: %w", err)
+	}
+
+	return nil
+}
+
+// chordNumber returns the chord number of the chord with index i in the set, as in the name of the file it was
+// converted from (see ExportCSV and ExportChordSetToMIDI).
+func chordNumber(set ChordSet, i int) int {
+	slot := i + 1
+	if set.Chords[i].slot > 0 {
+		slot = set.Chords[i].slot
+	}
+
+	return slot + set.numberOffset
+}
@@ -0,0 +1,151 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestExportChordSetToMIDISanitizesFileNames(t *testing.T) {
+	set := ChordSet{Name: "Set", Chords: []Chord{
+		{Name: "C/E", Notes: []int{4, 7, 12}},
+		{Name: "x/../../evil", Notes: []int{0, 3, 7}},
+	}}
+	outDir := filepath.Join(t.TempDir(), "out")
+
+	if err := ExportChordSetToMIDI(set, outDir, 60); err != nil {
+		t.Fatalf("ExportChordSetToMIDI() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	want := []string{"1 C_E.mid", "2 x_.._.._evil.mid"}
+	if !slices.Equal(names, want) {
+		t.Errorf("files = %q, want %q", names, want)
+	}
+}
+
+func TestExportChordSetToMIDIRoundTrip(t *testing.T) {
+	set := ChordSet{Name: "Set", Chords: []Chord{{Name: "Cmaj", Notes: []int{0, 4, 7}}, {Name: "Chd 2", Notes: []int{}}}}
+	root := t.TempDir()
+
+	if err := ExportChordSetToMIDI(set, filepath.Join(root, "Set"), 60); err != nil {
+		t.Fatalf("ExportChordSetToMIDI() error = %v", err)
+	}
+
+	c := testConverter()
+	c.SetMaxChords(2)
+	sets := convertFS(t, &c, os.DirFS(root))
+	if len(sets) != 1 {
+		t.Fatalf("got %d sets, want 1", len(sets))
+	}
+	if got := sets[0].Chords[0]; got.Name != "Cmaj" || !slices.Equal(got.Notes, []int{0, 4, 7}) {
+		t.Errorf("chord 1 = %+v, want Cmaj [0 4 7]", got)
+	}
+	if got := sets[0].Chords[1]; len(got.Notes) != 0 {
+		t.Errorf("chord 2 = %+v, want empty", got)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	sets := []ChordSet{{Name: "Jazz, Vol. 1", Chords: []Chord{
+		{Name: "Cmaj7", Notes: []int{0, 4, 7, 11}},
+		{Name: "Chd 2", Notes: []int{}},
+	}}}
+
+	var buf strings.Builder
+	if err := ExportCSV(sets, &buf); err != nil {
+		t.Fatalf("ExportCSV() error = %v", err)
+	}
+
+	want := "set,number,chord,notes\n" +
+		"\"Jazz, Vol. 1\",1,Cmaj7,\"0,4,7,11\"\n" +
+		"\"Jazz, Vol. 1\",2,Chd 2,\n"
+	if got := buf.String(); got != want {
+		t.Errorf("ExportCSV() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExportCSVChordNumbers(t *testing.T) {
+	fsys := fstest.MapFS{
+		"SA/4 Gmaj.mid": smfFile(smfChord(t, 67, 71, 74)),
+	}
+
+	for _, tt := range []struct {
+		name       string
+		numberBase int
+		want       string
+	}{
+		{"one-based", 1, "SA,4,Gmaj,\"7,11,14\"\n"},
+		{"zero-based", 0, "SA,4,Gmaj,\"7,11,14\"\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testConverter()
+			c.SetChordNumberBase(tt.numberBase)
+			c.SetOmitEmptyChords(true)
+			sets := convertFS(t, &c, fsys)
+
+			var buf strings.Builder
+			if err := ExportCSV(sets, &buf); err != nil {
+				t.Fatalf("ExportCSV() error = %v", err)
+			}
+			if got := strings.TrimPrefix(buf.String(), "set,number,chord,notes\n"); got != tt.want {
+				t.Errorf("ExportCSV() rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportChordSetToMIDIChordNumbers(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/2 Dmin.mid": smfFile(smfChord(t, 62, 65, 69)),
+		"Set/5 Gmaj.mid": smfFile(smfChord(t, 67, 71, 74)),
+	}
+
+	for _, tt := range []struct {
+		name       string
+		numberBase int
+		want       []string
+	}{
+		{"one-based", 1, []string{"2 Dmin.mid", "5 Gmaj.mid"}},
+		{"zero-based", 0, []string{"2 Dmin.mid", "5 Gmaj.mid"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testConverter()
+			c.SetChordNumberBase(tt.numberBase)
+			c.SetOmitEmptyChords(true)
+			sets := convertFS(t, &c, fsys)
+
+			root := t.TempDir()
+			if err := ExportChordSetToMIDI(sets[0], filepath.Join(root, "Set"), 60); err != nil {
+				t.Fatalf("ExportChordSetToMIDI() error = %v", err)
+			}
+			if got := dirNames(t, filepath.Join(root, "Set")); !slices.Equal(got, tt.want) {
+				t.Errorf("files = %q, want %q", got, tt.want)
+			}
+
+			// converting the exported files again gives the same set
+			rc := testConverter()
+			rc.SetChordNumberBase(tt.numberBase)
+			rc.SetOmitEmptyChords(true)
+			roundTrip := convertFS(t, &rc, os.DirFS(root))
+			if !chordsEqual(roundTrip[0].Chords, sets[0].Chords) {
+				t.Errorf("round trip chords = %+v, want %+v", roundTrip[0].Chords, sets[0].Chords)
+			}
+			for i := range roundTrip[0].Chords {
+				if got, want := chordNumber(roundTrip[0], i), chordNumber(sets[0], i); got != want {
+					t.Errorf("round trip chord %d number = %d, want %d", i+1, got, want)
+				}
+			}
+		})
+	}
+}
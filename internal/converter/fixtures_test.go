@@ -0,0 +1,192 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"gitlab.com/gomidi/midi/smf"
+	"gitlab.com/gomidi/midi/writer"
+)
+
+// smfData returns a Standard MIDI File with a single track written by write.
+// The events are written as is, so fixtures may contain repeated NoteOns of the same key.
+func smfData(t testing.TB, write func(wr *writer.SMF) error) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	wr := writer.NewSMF(&buf, 1)
+	wr.ConsolidateNotes(false)
+	if err := write(wr); err != nil {
+		t.Fatalf("writing MIDI fixture: %v", err)
+	}
+	if err := writer.EndOfTrack(wr); err != nil && !errors.Is(err, smf.ErrFinished) {
+		t.Fatalf("writing MIDI fixture: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+// smfChord returns a Standard MIDI File with the keys played simultaneously for one bar.
+func smfChord(t testing.TB, keys ...uint8) []byte {
+	t.Helper()
+
+	return smfNamedChord(t, "", keys...)
+}
+
+// smfNamedChord is like smfChord, but also stores the name as the track name, unless it is empty.
+func smfNamedChord(t testing.TB, name string, keys ...uint8) []byte {
+	t.Helper()
+
+	return smfData(t, func(wr *writer.SMF) error {
+		if name != "" {
+			if err := writer.TrackSequenceName(wr, name); err != nil {
+				return err
+			}
+		}
+		for _, key := range keys {
+			if err := writer.NoteOn(wr, key, 100); err != nil {
+				return err
+			}
+		}
+		wr.SetDelta(wr.Ticks4th() * 4)
+		for _, key := range keys {
+			if err := writer.NoteOff(wr, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// smfFile returns a file of an fstest.MapFS with the data.
+func smfFile(data []byte) *fstest.MapFile {
+	return &fstest.MapFile{Data: data, Mode: 0644}
+}
+
+// testConverter returns a Converter with the default configuration that doesn't log.
+func testConverter() Converter {
+	c := New()
+	c.SetLogger(slog.New(slog.DiscardHandler))
+
+	return c
+}
+
+// convertFS converts the sets of fsys and fails the test on an error.
+func convertFS(t testing.TB, c *Converter, fsys fs.FS) []ChordSet {
+	t.Helper()
+
+	sets, err := c.ConvertFolder(fsys, ".")
+	if err != nil {
+		t.Fatalf("ConvertFolder() error = %v", err)
+	}
+
+	return sets
+}
+
+// writeTree writes the files of fsys to a new temporary folder and returns its path.
+func writeTree(t testing.TB, fsys fstest.MapFS) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for name, file := range fsys {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, file.Data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+// chordNames returns the names of the chords.
+func chordNames(chords []Chord) []string {
+	names := make([]string, 0, len(chords))
+	for _, chord := range chords {
+		names = append(names, chord.Name)
+	}
+
+	return names
+}
+
+// setNames returns the names of the chord sets.
+func setNames(sets []ChordSet) []string {
+	names := make([]string, 0, len(sets))
+	for _, set := range sets {
+		names = append(names, set.Name)
+	}
+
+	return names
+}
+
+// dirNames returns the sorted names of the entries of the folder.
+func dirNames(t testing.TB, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	return names
+}
+
+// fixtureTree returns a folder with the given number of sets, each with a MIDI file for every chord slot.
+func fixtureTree(t testing.TB, sets, chords int) fstest.MapFS {
+	t.Helper()
+
+	fsys := fstest.MapFS{}
+	for s := range sets {
+		for n := range chords {
+			root := uint8(48 + (s+n)%12)
+			name := fmt.Sprintf("Set %02d/%d Chord %d.mid", s+1, n+1, n+1)
+			fsys[name] = smfFile(smfChord(t, root, root+4, root+7, root+11))
+		}
+	}
+
+	return fsys
+}
+
+// writeFile writes the data to a file in a new temporary folder and returns its path.
+func writeFile(t testing.TB, name, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// rawSMF returns a Standard MIDI File of the format (0, 1 or 2) with 96 ticks per quarter note and the tracks,
+// which are given as raw event data: single-byte delta times followed by MIDI messages, e.g. to use running status.
+// The End of Track event is appended to each track.
+func rawSMF(format uint16, tracks ...[]byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("MThd")
+	buf.Write([]byte{0, 0, 0, 6, 0, byte(format), 0, byte(len(tracks)), 0, 96})
+	for _, track := range tracks {
+		track = append(slices.Clone(track), 0x00, 0xFF, 0x2F, 0x00)
+		buf.WriteString("MTrk")
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(len(track))))
+		buf.Write(track)
+	}
+
+	return buf.Bytes()
+}
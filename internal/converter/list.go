@@ -0,0 +1,108 @@
+package converter
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"maschine_chords_converter/internal/helpers"
+)
+
+// SetInfo describes a set folder found in the sets folder.
+type SetInfo struct {
+	Name  string // set name (from the set.json file of the folder, if it sets one)
+	Path  string // path of the set folder
+	Files int    // number of MIDI files in the set folder, whether or not their names are valid
+}
+
+// ListSets returns the set folders that Run would process, in the order of the sets, with the number of
+// MIDI files in each. The folders are selected by the same rules as in Run (name length, ignore and include
+// patterns, maximum depth and number of sets), but no MIDI files are read and no files are written.
+func (c *Converter) ListSets() ([]SetInfo, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := c.getSetsFolder(); err != nil {
+		return nil, err
+	}
+
+	fsys, osRoot := c.inputFS()
+
+	return c.listSets(fsys, osRoot, c.setsFolder)
+}
+
+// ListSetsZip is like ListSets, but lists the set folders of a zip archive (see RunZip).
+func (c *Converter) ListSetsZip(zipPath string) ([]SetInfo, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening zip archive %s: %w", zipPath, err)
+	}
+	defer zr.Close()
+
+	return c.listSets(zr, zipPath, filepath.Dir(zipPath))
+}
+
+// listSets lists the set folders of fsys. osRoot and outDir are used as in run.
+func (c *Converter) listSets(fsys fs.FS, osRoot, outDir string) ([]SetInfo, error) {
+	c.issues = nil
+	c.fsys, c.osRoot = fsys, osRoot
+	c.fsRoot = "."
+	c.outDir = outDir
+	c.outPattern = c.outputFilePattern()
+
+	folders, err := c.findSetFolders()
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make([]SetInfo, 0, len(folders))
+	for _, folder := range folders {
+		files, err := c.countMidiFiles(folder.path)
+		if err != nil {
+			return nil, fmt.Errorf("error listing set folder %s: %w", c.displayPath(folder.path), err)
+		}
+		config, _, err := c.readFolderConfig(folder.path)
+		if err != nil {
+			return nil, err
+		}
+		name := helpers.NormalizeName(folder.name)
+		if config.Name != "" {
+			name = helpers.NormalizeName(config.Name)
+		}
+		sets = append(sets, SetInfo{Name: name, Path: c.displayPath(folder.path), Files: files})
+	}
+
+	return sets, nil
+}
+
+// countMidiFiles returns the number of MIDI files in the set folder that processOneSetFolder would consider,
+// i.e. including subfolders unless disabled and excluding hidden and ignored subfolders.
+func (c *Converter) countMidiFiles(folder string) (int, error) {
+	count := 0
+	err := walkDirSorted(c.fsys, folder, func(filePath string, file fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if file.IsDir() {
+			if filePath != folder && (c.flatSets || c.isIgnoredFolder(file.Name())) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if c.isMidiFile(file.Name()) && !c.isOutputFile(filePath) {
+			count++
+		}
+
+		return nil
+	})
+
+	return count, err
+}
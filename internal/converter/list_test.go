@@ -0,0 +1,76 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestListSets(t *testing.T) {
+	chord := smfFile(smfChord(t, 60, 64, 67))
+	root := writeTree(t, fstest.MapFS{
+		"Bass/1 Cmaj.mid":                 chord,
+		"Bass/2 Dmin.mid":                 chord,
+		"Bass/notes.txt":                  &fstest.MapFile{Data: []byte("not a MIDI file")},
+		"Keys/1 Cmaj.mid":                 chord,
+		"Keys/set.json":                   &fstest.MapFile{Data: []byte(`{"name": "Piano"}`)},
+		"Pads/Layer/1 Cmaj.mid":           chord,
+		"Pads/2 Cmaj.mid":                 chord,
+		".hidden/1 Cmaj.mid":              chord,
+		"Drafts/1 Cmaj.mid":               chord,
+		"A very long set name/1 Cmaj.mid": chord,
+		"Zeta/1 Cmaj.mid":                 chord,
+	})
+
+	c := testConverter()
+	c.SetSetsFolder(root)
+	c.SetIgnorePatterns("Draft*")
+	c.SetMaxSets(3)
+	sets, err := c.ListSets()
+	if err != nil {
+		t.Fatalf("ListSets() error = %v", err)
+	}
+
+	want := []SetInfo{
+		{Name: "Bass", Path: filepath.Join(root, "Bass"), Files: 2},
+		{Name: "Piano", Path: filepath.Join(root, "Keys"), Files: 1},
+		{Name: "Pads", Path: filepath.Join(root, "Pads"), Files: 2},
+	}
+	if !reflect.DeepEqual(sets, want) {
+		t.Errorf("ListSets() = %+v, want %+v", sets, want)
+	}
+
+	// nothing is written to the sets folder
+	if got, want := dirNames(t, root), []string{".hidden", "A very long set name", "Bass", "Drafts", "Keys", "Pads", "Zeta"}; !slices.Equal(got, want) {
+		t.Errorf("sets folder entries = %q, want %q", got, want)
+	}
+}
+
+func TestListSetsZip(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "pack.zip")
+	data := zipData(t, fstest.MapFS{
+		"Piano/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"Piano/2 Dmin.mid": smfFile(smfChord(t, 62, 65, 69)),
+		"Pads/3 Emin.mid":  smfFile(smfChord(t, 64, 67, 71)),
+	})
+	if err := os.WriteFile(zipPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := testConverter()
+	sets, err := c.ListSetsZip(zipPath)
+	if err != nil {
+		t.Fatalf("ListSetsZip() error = %v", err)
+	}
+
+	want := []SetInfo{
+		{Name: "Pads", Path: filepath.Join(zipPath, "Pads"), Files: 1},
+		{Name: "Piano", Path: filepath.Join(zipPath, "Piano"), Files: 2},
+	}
+	if !reflect.DeepEqual(sets, want) {
+		t.Errorf("ListSetsZip() = %+v, want %+v", sets, want)
+	}
+}
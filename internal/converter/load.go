@@ -0,0 +1,32 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// LoadChordSet reads a chord set JSON file (e.g. "user_chord_set_01.json") into a ChordSet.
+// It checks the basic structure only: the file must contain a non-empty list of chords and a type id.
+// Use ValidateChordSet for a full check.
+func LoadChordSet(path string) (ChordSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ChordSet{}, fmt.Errorf("error reading chord set file %s: %w", path, err)
+	}
+
+	var set ChordSet
+	if err = json.Unmarshal(data, &set); err != nil {
+		return ChordSet{}, fmt.Errorf("error parsing chord set file %s: %w", path, err)
+	}
+
+	if len(set.Chords) == 0 {
+		return ChordSet{}, fmt.Errorf("invalid chord set file %s: no chords", path)
+	}
+
+	if set.TypeID == "" {
+		return ChordSet{}, fmt.Errorf("invalid chord set file %s: missing type id", path)
+	}
+
+	return set, nil
+}
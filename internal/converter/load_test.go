@@ -0,0 +1,57 @@
+package converter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const chordSetJSON = `{
+  "chords": [
+    {"name": "Cmaj7", "notes": [0, 4, 7, 11]},
+    {"name": "Chd 2", "notes": []}
+  ],
+  "name": "Jazz",
+  "typeId": "native-instruments-chord-set",
+  "uuid": "3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b",
+  "version": "1.0.0"
+}`
+
+func TestLoadChordSet(t *testing.T) {
+	set, err := LoadChordSet(writeFile(t, "user_chord_set_01.json", chordSetJSON))
+	if err != nil {
+		t.Fatalf("LoadChordSet() error = %v", err)
+	}
+
+	want := ChordSet{
+		Chords:  []Chord{{Name: "Cmaj7", Notes: []int{0, 4, 7, 11}}, {Name: "Chd 2", Notes: []int{}}},
+		Name:    "Jazz",
+		TypeID:  "native-instruments-chord-set",
+		UUID:    "3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b",
+		Version: "1.0.0",
+	}
+	if !reflect.DeepEqual(set, want) {
+		t.Errorf("LoadChordSet() = %+v, want %+v", set, want)
+	}
+}
+
+func TestLoadChordSetInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"malformed", `{"chords": [`, "error parsing chord set file"},
+		{"no chords", `{"chords": [], "typeId": "native-instruments-chord-set"}`, "no chords"},
+		{"no type id", `{"chords": [{"name": "C", "notes": [0]}]}`, "missing type id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, "set.json", tt.data)
+			_, err := LoadChordSet(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.Contains(err.Error(), path) {
+				t.Errorf("LoadChordSet() error = %v, want %q naming the file", err, tt.want)
+			}
+		})
+	}
+}
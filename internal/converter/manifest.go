@@ -0,0 +1,54 @@
+package converter
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// manifestFileName is the name of the manifest written to the output folder (see SetManifest).
+const manifestFileName = "index.json"
+
+// Manifest lists the chord set files generated by a run, as written to the index.json file.
+type Manifest struct {
+	Version string          `json:"version"` // version of the utility that generated the files
+	Files   []ManifestEntry `json:"files"`   // generated files in the order of the sets
+}
+
+// ManifestEntry describes a generated chord set file.
+type ManifestEntry struct {
+	File   string `json:"file"`   // file name within the output folder
+	Name   string `json:"name"`   // set name
+	Chords int    `json:"chords"` // number of chords with notes
+	UUID   string `json:"uuid"`   // UUID of the set
+}
+
+// newManifestEntry returns the manifest entry of the chord set written to the file.
+func newManifestEntry(fileName string, set ChordSet) ManifestEntry {
+	return ManifestEntry{File: fileName, Name: set.Name, Chords: len(nonEmptyChords(set.Chords)), UUID: set.UUID}
+}
+
+// writeManifest writes the manifest with the entries to the index.json file in the output folder.
+func (c *Converter) writeManifest(outFolder string, entries []ManifestEntry) error {
+	if entries == nil {
+		entries = []ManifestEntry{}
+	}
+
+	jsonData, err := c.marshalJSON(Manifest{Version: version, Files: entries})
+	if err != nil {
+		return fmt.Errorf("error marshaling manifest: %w", err)
+	}
+
+	path := filepath.Join(outFolder, manifestFileName)
+	if c.dryRun {
+		c.logger.Info("dry run: would write file", "path", path, "bytes", len(jsonData))
+		return nil
+	}
+
+	if err := c.writeOutputFile(path, jsonData); err != nil {
+		return fmt.Errorf("error writing manifest %s: %w", path, err)
+	}
+
+	c.logger.Info("generated file", "path", path)
+
+	return nil
+}
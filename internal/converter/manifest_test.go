@@ -0,0 +1,60 @@
+package converter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	sets := []ChordSet{
+		{Name: "Jazz", UUID: "u1", Chords: []Chord{{Name: "Cmaj7", Notes: []int{0, 4, 7, 11}}, {Name: "Chd 2", Notes: []int{}}}},
+		{Name: "Pop", UUID: "u2", Chords: []Chord{{Name: "C", Notes: []int{0, 4, 7}}, {Name: "G", Notes: []int{7, 11, 14}}}},
+	}
+
+	outDir := t.TempDir()
+	c := testConverter()
+	c.SetOutputFolder(outDir)
+	c.SetManifest(true)
+	c.chordSets = sets
+	if err := c.outputFiles(); err != nil {
+		t.Fatalf("outputFiles() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, manifestFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ManifestEntry{
+		{File: "user_chord_set_01.json", Name: "Jazz", Chords: 1, UUID: "u1"},
+		{File: "user_chord_set_02.json", Name: "Pop", Chords: 2, UUID: "u2"},
+	}
+	if !reflect.DeepEqual(manifest.Files, want) {
+		t.Errorf("manifest files = %+v, want %+v", manifest.Files, want)
+	}
+	if manifest.Version != version {
+		t.Errorf("manifest version = %q, want %q", manifest.Version, version)
+	}
+}
+
+func TestManifestDisabled(t *testing.T) {
+	outDir := t.TempDir()
+	c := testConverter()
+	c.SetOutputFolder(outDir)
+	c.chordSets = []ChordSet{{Name: "Jazz", Chords: []Chord{{Name: "C", Notes: []int{0, 4, 7}}}}}
+	if err := c.outputFiles(); err != nil {
+		t.Fatalf("outputFiles() error = %v", err)
+	}
+
+	if got, want := dirNames(t, outDir), []string{"user_chord_set_01.json"}; !slices.Equal(got, want) {
+		t.Errorf("output files = %q, want %q", got, want)
+	}
+}
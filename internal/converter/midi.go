@@ -0,0 +1,343 @@
+package converter
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gitlab.com/gomidi/midi/reader"
+
+	"maschine_chords_converter/internal/helpers"
+)
+
+// midiNote is a note read from a MIDI file.
+type midiNote struct {
+	value    int    // note value, relative to the base note or absolute depending on the note mode
+	velocity int    // velocity of the note
+	tick     uint64 // absolute tick of the note onset
+}
+
+// readMidiName reads the chord name from a Standard MIDI File. The first track name is preferred,
+// otherwise the first text meta event is used. An empty string is returned if neither is present.
+// If the file is corrupt, the name read before the corrupt part is returned along with the error.
+func readMidiName(r io.Reader) (string, error) {
+	var trackName, text string
+
+	rd := reader.New(
+		reader.NoLogger(),
+		reader.TrackSequenceName(func(pos reader.Position, name string) {
+			if trackName == "" {
+				trackName = strings.TrimSpace(name)
+			}
+		}),
+		reader.Text(func(pos reader.Position, t string) {
+			if text == "" {
+				text = strings.TrimSpace(t)
+			}
+		}),
+	)
+
+	err := reader.ReadSMF(rd, r)
+
+	if trackName != "" {
+		return trackName, err
+	}
+
+	return text, err
+}
+
+// MidiReader reads the notes of a chord from MIDI data.
+// It can be replaced with SetMidiReader, e.g. to read other formats or to provide notes without files.
+type MidiReader interface {
+	// ReadNotes returns the distinct notes of the chord in the order in which they were read.
+	// On an error, it may also return the notes read before the error, which are used in lenient mode.
+	ReadNotes(r io.Reader) ([]NoteEvent, error)
+}
+
+// NoteEvent is a note read by a MidiReader.
+type NoteEvent struct {
+	Note       int    // MIDI note number (0-127)
+	Velocity   int    // velocity of the note
+	Tick       uint64 // absolute tick of the note onset
+	Duplicates int    // number of further NoteOns of the same note collapsed into this one (e.g. on other channels)
+}
+
+// smfReader is the default MidiReader, which reads Standard MIDI Files.
+type smfReader struct {
+	channel int // channel filter: 1-16 or allChannels
+	tick    int // if not negative, only notes held at this tick are read
+}
+
+// ReadNotes reads the notes from a Standard MIDI File. If the file is corrupt, the notes read before
+// the corrupt part are returned along with the error.
+func (s smfReader) ReadNotes(r io.Reader) ([]NoteEvent, error) {
+	if s.tick >= 0 {
+		return s.readNotesAtTime(r, uint32(s.tick))
+	}
+
+	return s.readAllNotes(r)
+}
+
+// readAllNotes reads all distinct notes with the velocity and the tick of their first NoteOn.
+func (s smfReader) readAllNotes(r io.Reader) ([]NoteEvent, error) {
+	var notes []NoteEvent
+	index := make(map[uint8]int) // index of each read key in notes
+
+	rd := reader.New(
+		reader.NoLogger(),
+		reader.NoteOn(func(pos *reader.Position, ch, key, vel uint8) {
+			if vel == 0 || !matchChannel(s.channel, ch) {
+				return
+			}
+			if i, ok := index[key]; ok {
+				notes[i].Duplicates++
+				return
+			}
+			index[key] = len(notes)
+			notes = append(notes, NoteEvent{Note: int(key), Velocity: int(vel), Tick: pos.AbsoluteTicks})
+		}),
+	)
+
+	err := reader.ReadSMF(rd, r)
+
+	return notes, err
+}
+
+// heldNote identifies a sounding note within a MIDI file.
+type heldNote struct {
+	track   int16
+	channel uint8
+	key     uint8
+}
+
+// readNotesAtTime reads the notes that are held at the given tick with the velocity and the tick of their last NoteOn.
+// A note is held if its NoteOn happened at or before the tick and its NoteOff (or NoteOn with velocity 0) after it.
+// Overlapping NoteOns of the same key and channel are counted, so that the note is held until all of them end.
+// This happens when a DAW merges several tracks into a single track (SMF type 0).
+func (s smfReader) readNotesAtTime(r io.Reader, tick uint32) ([]NoteEvent, error) {
+	var order []heldNote                 // notes in order of their first NoteOn
+	held := make(map[heldNote]NoteEvent) // last NoteOn of each note
+	active := make(map[heldNote]int)     // number of sounding instances of each note
+
+	rd := reader.New(
+		reader.NoLogger(),
+		reader.NoteOn(func(pos *reader.Position, channel, key, vel uint8) {
+			if vel == 0 || pos.AbsoluteTicks > uint64(tick) || !matchChannel(s.channel, channel) {
+				return
+			}
+			n := heldNote{track: pos.Track, channel: channel, key: key}
+			if _, ok := held[n]; !ok {
+				order = append(order, n)
+			}
+			held[n] = NoteEvent{Note: int(key), Velocity: int(vel), Tick: pos.AbsoluteTicks}
+			active[n]++
+		}),
+		reader.NoteOff(func(pos *reader.Position, channel, key, vel uint8) {
+			if pos.AbsoluteTicks > uint64(tick) {
+				return
+			}
+			n := heldNote{track: pos.Track, channel: channel, key: key}
+			if active[n] > 0 {
+				active[n]--
+			}
+		}),
+	)
+
+	err := reader.ReadSMF(rd, r) // on an error, the notes held at the end of the read part are returned
+
+	var notes []NoteEvent
+	index := make(map[uint8]int) // index of each held key in notes
+	for _, n := range order {
+		if active[n] == 0 {
+			continue
+		}
+		if i, ok := index[n.key]; ok {
+			notes[i].Duplicates += active[n]
+			continue
+		}
+		index[n.key] = len(notes)
+		note := held[n]
+		note.Duplicates = active[n] - 1
+		notes = append(notes, note)
+	}
+
+	return notes, err
+}
+
+// matchChannel reports whether a MIDI channel (0-15) matches the channel filter (1-16 or allChannels).
+func matchChannel(filter int, channel uint8) bool {
+	return filter == allChannels || int(channel)+1 == filter
+}
+
+// readNotes reads the notes with the MIDI reader and returns them with values relative to baseNote
+// (0 for absolute values), along with the number of duplicate NoteOns collapsed by the reader.
+// On an error, the notes read before it are returned along with the error.
+func readNotes(mr MidiReader, r io.Reader, baseNote int) ([]midiNote, int, error) {
+	read, err := mr.ReadNotes(r)
+
+	notes := make([]midiNote, 0, len(read))
+	duplicates := 0
+	for _, n := range read {
+		notes = append(notes, midiNote{value: n.Note - baseNote, velocity: n.Velocity, tick: n.Tick})
+		duplicates += n.Duplicates
+	}
+
+	return notes, duplicates, err
+}
+
+// ChordFromMIDI converts a single MIDI file into a Chord.
+// The chord name is parsed from the file name, and the notes are read relative to baseNote and sorted.
+// If the file contains no notes, an error wrapping ErrNoNotes is returned.
+func ChordFromMIDI(path string, baseNote int) (Chord, error) {
+	_, name, err := parseChordFileName(re, filepath.Base(path))
+	if err != nil {
+		return Chord{}, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return Chord{}, fmt.Errorf("failed to read MIDI file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	notes, _, err := readNotes(smfReader{channel: allChannels, tick: -1}, f, baseNote)
+	if err != nil {
+		return Chord{}, fmt.Errorf("failed to read MIDI file %s: %w", path, err)
+	}
+	if len(notes) == 0 {
+		return Chord{}, fmt.Errorf("%s: %w", path, ErrNoNotes)
+	}
+	sortNotes(notes)
+
+	return Chord{Name: name, Notes: noteValues(notes)}, nil
+}
+
+// midiReader returns the MIDI reader set with SetMidiReader or the default reader configured
+// with the channel filter and the reference tick.
+func (c *Converter) midiReader() MidiReader {
+	if c.reader != nil {
+		return c.reader
+	}
+
+	return smfReader{channel: c.channel, tick: c.refTick}
+}
+
+// readChordNotes reads notes from a MIDI file and returns them with values according to the note mode and
+// the base note of the set, transposed by the configured interval and remapped. If the reference tick is set, only notes sounding at that tick are returned.
+// The number of duplicate NoteOns of the same notes (e.g. doubled on several channels) is returned as well.
+func (c *Converter) readChordNotes(path string, baseNote int) ([]midiNote, int, error) {
+	data, err := fs.ReadFile(c.fsys, path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read MIDI file %s: %w", c.displayPath(path), err)
+	}
+
+	notes, duplicates, err := readNotes(c.midiReader(), bytes.NewReader(data), c.noteOffset(baseNote))
+	if err != nil {
+		if !c.lenient || len(notes) == 0 {
+			return nil, 0, fmt.Errorf("failed to read MIDI file %s: %w", c.displayPath(path), err)
+		}
+		c.logger.Warn("MIDI file is corrupt, using the notes read before the error", "file", c.displayPath(path),
+			"notes", len(notes), "error", err)
+	}
+	if duplicates > 0 {
+		c.logger.Debug("duplicate notes collapsed", "file", c.displayPath(path), "count", duplicates)
+	}
+	c.logger.Log(context.Background(), LevelTrace, "MIDI notes read", "file", c.displayPath(path), "notes", c.absoluteNotes(notes, baseNote))
+
+	for i := range notes {
+		notes[i].value += c.transpose
+	}
+	if c.remap != nil {
+		notes = c.remapNotes(c.displayPath(path), notes)
+	}
+	c.logger.Log(context.Background(), LevelTrace, "note values", "file", c.displayPath(path), "mode", c.noteMode, "transpose", c.transpose, "values", noteValues(notes))
+
+	return notes, duplicates, nil
+}
+
+// noteOffset returns the value subtracted from MIDI note numbers: the base note in relative mode, 0 in absolute mode.
+func (c *Converter) noteOffset(baseNote int) int {
+	if c.noteMode == NoteModeAbsolute {
+		return 0
+	}
+
+	return baseNote
+}
+
+// absoluteNotes returns the MIDI note numbers of the notes read by readChordNotes with the base note.
+func (c *Converter) absoluteNotes(notes []midiNote, baseNote int) []int {
+	values := make([]int, 0, len(notes))
+	for _, n := range notes {
+		values = append(values, n.value+c.noteOffset(baseNote))
+	}
+
+	return values
+}
+
+// noteName returns the name of the note read by readChordNotes with the base note, with the octave, e.g. "C#3".
+func (c *Converter) noteName(note midiNote, baseNote int) string {
+	return helpers.NoteName(note.value+c.noteOffset(baseNote), c.noteNaming)
+}
+
+// appendUniqueNote appends the note to the slice unless a note with the same value is already present.
+func appendUniqueNote(notes []midiNote, note midiNote) []midiNote {
+	if slices.ContainsFunc(notes, func(n midiNote) bool { return n.value == note.value }) {
+		return notes
+	}
+
+	return append(notes, note)
+}
+
+// sortNotes sorts notes by value in ascending order.
+func sortNotes(notes []midiNote) {
+	slices.SortFunc(notes, func(a, b midiNote) int { return cmp.Compare(a.value, b.value) })
+}
+
+// normalizeVoicing moves the notes into root position within one octave (see helpers.NormalizeChord).
+// Notes folded onto the same value keep the velocity and the onset of the first of them.
+func normalizeVoicing(notes []midiNote) []midiNote {
+	firsts := make(map[int]midiNote, len(notes)) // first note of each pitch class
+	for _, n := range notes {
+		pc := (n.value%12 + 12) % 12
+		if _, ok := firsts[pc]; !ok {
+			firsts[pc] = n
+		}
+	}
+
+	values := helpers.NormalizeChord(noteValues(notes))
+	result := make([]midiNote, 0, len(values))
+	for _, v := range values {
+		n := firsts[(v%12+12)%12]
+		n.value = v
+		result = append(result, n)
+	}
+
+	return result
+}
+
+// noteValues returns the values of the notes.
+func noteValues(notes []midiNote) []int {
+	values := make([]int, 0, len(notes))
+	for _, n := range notes {
+		values = append(values, n.value)
+	}
+
+	return values
+}
+
+// notesWithVelocity returns the notes as NoteVel values.
+func notesWithVelocity(notes []midiNote) []NoteVel {
+	result := make([]NoteVel, 0, len(notes))
+	for _, n := range notes {
+		result = append(result, NoteVel{Note: n.value, Velocity: n.velocity})
+	}
+
+	return result
+}
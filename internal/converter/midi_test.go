@@ -0,0 +1,523 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gitlab.com/gomidi/midi/writer"
+)
+
+// twoChannelChord returns a MIDI file with a C major triad on channel 1 and a bass note on channel 2.
+func twoChannelChord(t *testing.T) []byte {
+	t.Helper()
+
+	notes := []struct{ channel, key uint8 }{{0, 60}, {0, 64}, {0, 67}, {1, 48}}
+
+	return smfData(t, func(wr *writer.SMF) error {
+		for _, n := range notes {
+			wr.SetChannel(n.channel)
+			if err := writer.NoteOn(wr, n.key, 100); err != nil {
+				return err
+			}
+		}
+		wr.SetDelta(wr.Ticks4th() * 4)
+		for _, n := range notes {
+			wr.SetChannel(n.channel)
+			if err := writer.NoteOff(wr, n.key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func TestChannelFilter(t *testing.T) {
+	fsys := fstest.MapFS{"Set/1 Cmaj.mid": smfFile(twoChannelChord(t))}
+
+	for _, tt := range []struct {
+		channel int
+		want    []int
+	}{
+		{0, []int{-12, 0, 4, 7}},
+		{1, []int{0, 4, 7}},
+		{2, []int{-12}},
+		{3, []int{}},
+	} {
+		c := testConverter()
+		c.SetChannelFilter(tt.channel)
+		sets := convertFS(t, &c, fsys)
+
+		if got := sets[0].Chords[0].Notes; !slices.Equal(got, tt.want) {
+			t.Errorf("channel %d: notes = %v, want %v", tt.channel, got, tt.want)
+		}
+	}
+}
+
+func TestKeepVelocity(t *testing.T) {
+	chord := smfData(t, func(wr *writer.SMF) error {
+		for _, n := range []struct{ key, vel uint8 }{{60, 90}, {64, 70}, {67, 127}} {
+			if err := writer.NoteOn(wr, n.key, n.vel); err != nil {
+				return err
+			}
+		}
+		wr.SetDelta(wr.Ticks4th())
+		return nil
+	})
+	fsys := fstest.MapFS{"Set/1 Cmaj.mid": smfFile(chord)}
+
+	c := testConverter()
+	c.SetKeepVelocity(true)
+	sets := convertFS(t, &c, fsys)
+
+	want := []NoteVel{{Note: 0, Velocity: 90}, {Note: 4, Velocity: 70}, {Note: 7, Velocity: 127}}
+	if got := sets[0].Chords[0]; !reflect.DeepEqual(got.NotesWithVelocity, want) || !slices.Equal(got.Notes, []int{0, 4, 7}) {
+		t.Errorf("chord 1 = %+v, want notes [0 4 7] with velocities %v", got, want)
+	}
+	if got := sets[0].Chords[1].NotesWithVelocity; got != nil {
+		t.Errorf("empty chord velocities = %v, want none", got)
+	}
+
+	c.SetKeepVelocity(false)
+	sets = convertFS(t, &c, fsys)
+	data, err := json.Marshal(sets[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "notesWithVelocity") {
+		t.Errorf("JSON without velocities contains notesWithVelocity: %s", data)
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"Set/2 Amin.mid": smfFile(smfChord(t, 45, 60, 64)),
+	}
+
+	c := testConverter()
+	c.SetMaxChords(2)
+	c.SetTranspose(12)
+	sets := convertFS(t, &c, fsys)
+	for i, want := range [][]int{{12, 16, 19}, {-3, 12, 16}} {
+		if got := sets[0].Chords[i].Notes; !slices.Equal(got, want) {
+			t.Errorf("chord %d notes = %v, want %v", i+1, got, want)
+		}
+	}
+
+	c.SetTranspose(-12)
+	c.SetNoteRange(-12, 12, NoteRangeSkip)
+	sets = convertFS(t, &c, fsys)
+	if got, want := sets[0].Chords[1].Notes, []int{-12, -8}; !slices.Equal(got, want) {
+		t.Errorf("transposed down with skip policy: chord 2 notes = %v, want %v", got, want)
+	}
+}
+
+func TestNormalizeVoicing(t *testing.T) {
+	fsys := fstest.MapFS{"Set/1 Cmaj.mid": smfFile(smfChord(t, 48, 64, 79, 84))}
+
+	c := testConverter()
+	c.SetNormalizeVoicing(true)
+	sets := convertFS(t, &c, fsys)
+
+	if got, want := sets[0].Chords[0].Notes, []int{-12, -8, -5}; !slices.Equal(got, want) {
+		t.Errorf("notes = %v, want %v", got, want)
+	}
+}
+
+// fakeReader is a MidiReader that takes the notes from the data, one byte per note.
+type fakeReader struct{}
+
+func (fakeReader) ReadNotes(r io.Reader) ([]NoteEvent, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("no data")
+	}
+
+	notes := make([]NoteEvent, 0, len(data))
+	for _, b := range data {
+		notes = append(notes, NoteEvent{Note: int(b), Velocity: 100})
+	}
+
+	return notes, nil
+}
+
+func TestMidiReader(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/1 Cmaj.mid":  {Data: []byte{67, 60, 64}},
+		"Set/2 Empty.mid": {Data: []byte{}},
+	}
+
+	c := testConverter()
+	c.SetMaxChords(2)
+	c.SetMidiReader(fakeReader{})
+	_, err := c.ConvertFolder(fsys, ".")
+	if err == nil || !strings.Contains(err.Error(), "no data") {
+		t.Fatalf("ConvertFolder() error = %v, want the reader error", err)
+	}
+
+	delete(fsys, "Set/2 Empty.mid")
+	sets := convertFS(t, &c, fsys)
+	if got, want := sets[0].Chords[0].Notes, []int{0, 4, 7}; !slices.Equal(got, want) {
+		t.Errorf("notes = %v, want %v", got, want)
+	}
+}
+
+func TestNoteSort(t *testing.T) {
+	// G, C, E and a repeated C, all starting at the same tick
+	fsys := fstest.MapFS{"Set/1 Cmaj.mid": smfFile(smfChord(t, 67, 60, 64, 60))}
+
+	for _, tt := range []struct {
+		order NoteSort
+		want  []int
+	}{
+		{NoteSortAscending, []int{0, 4, 7}},
+		{NoteSortDescending, []int{7, 4, 0}},
+		{NoteSortAsPlayed, []int{7, 0, 4}},
+	} {
+		c := testConverter()
+		c.SetNoteSort(tt.order)
+		sets := convertFS(t, &c, fsys)
+
+		if got := sets[0].Chords[0].Notes; !slices.Equal(got, tt.want) {
+			t.Errorf("%s: notes = %v, want %v", tt.order, got, tt.want)
+		}
+	}
+}
+
+// rolledChord returns a MIDI file with the keys starting one after another, 120 ticks apart.
+func rolledChord(t *testing.T, keys ...uint8) []byte {
+	t.Helper()
+
+	return smfData(t, func(wr *writer.SMF) error {
+		for _, key := range keys {
+			if err := writer.NoteOn(wr, key, 100); err != nil {
+				return err
+			}
+			wr.SetDelta(120)
+		}
+		for _, key := range keys {
+			if err := writer.NoteOff(wr, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func TestReadNotesOnsetTicks(t *testing.T) {
+	notes, err := smfReader{tick: -1}.ReadNotes(bytes.NewReader(rolledChord(t, 60, 67, 64)))
+	if err != nil {
+		t.Fatalf("ReadNotes() error = %v", err)
+	}
+
+	want := []NoteEvent{{Note: 60, Velocity: 100, Tick: 0}, {Note: 67, Velocity: 100, Tick: 120}, {Note: 64, Velocity: 100, Tick: 240}}
+	if !reflect.DeepEqual(notes, want) {
+		t.Errorf("ReadNotes() = %+v, want %+v", notes, want)
+	}
+}
+
+func TestNoteSortByOnset(t *testing.T) {
+	fsys := fstest.MapFS{"Set/1 Cmaj.mid": smfFile(rolledChord(t, 67, 60, 76, 64))}
+
+	c := testConverter()
+	c.SetNoteSort(NoteSortAsPlayed)
+	sets := convertFS(t, &c, fsys)
+
+	if got, want := sets[0].Chords[0].Notes, []int{7, 0, 16, 4}; !slices.Equal(got, want) {
+		t.Errorf("notes = %v, want %v", got, want)
+	}
+}
+
+func TestNoteRangeFold(t *testing.T) {
+	fsys := fstest.MapFS{"Set/1 Cmaj.mid": smfFile(smfChord(t, 24, 64, 96))}
+
+	c := testConverter()
+	c.SetNoteRange(-24, 24, NoteRangeFold)
+	sets := convertFS(t, &c, fsys)
+
+	if got, want := sets[0].Chords[0].Notes, []int{-24, 4, 24}; !slices.Equal(got, want) {
+		t.Errorf("notes = %v, want %v", got, want)
+	}
+}
+
+func TestTraceLogsNotes(t *testing.T) {
+	fsys := fstest.MapFS{"Set/1 Cmaj.mid": smfFile(smfChord(t, 67, 60, 64))}
+
+	for _, tt := range []struct {
+		level slog.Level
+		want  []string
+	}{
+		{slog.LevelDebug, []string{`msg="chord read" file="Set/1 Cmaj.mid" number=1 name=Cmaj notes="[0 4 7]"`}},
+		{LevelTrace, []string{
+			`msg="MIDI notes read" file="Set/1 Cmaj.mid" notes="[67 60 64]"`,
+			`msg="note values" file="Set/1 Cmaj.mid" mode=relative transpose=0 values="[7 0 4]"`,
+			`msg="chord read" file="Set/1 Cmaj.mid" number=1 name=Cmaj notes="[0 4 7]"`,
+		}},
+	} {
+		var log strings.Builder
+		c := testConverter()
+		c.SetLogger(slog.New(slog.NewTextHandler(&log, &slog.HandlerOptions{Level: tt.level})))
+		convertFS(t, &c, fsys)
+
+		for _, want := range tt.want {
+			if !strings.Contains(log.String(), want) {
+				t.Errorf("level %v: log doesn't contain %s:\n%s", tt.level, want, log.String())
+			}
+		}
+		if traced := strings.Contains(log.String(), "MIDI notes read"); traced != (tt.level == LevelTrace) {
+			t.Errorf("level %v: notes read logged = %v", tt.level, traced)
+		}
+	}
+}
+
+func TestDuplicateNotes(t *testing.T) {
+	layered := smfData(t, func(wr *writer.SMF) error {
+		for _, channel := range []uint8{0, 1} {
+			wr.SetChannel(channel)
+			for _, key := range []uint8{60, 64, 67} {
+				if err := writer.NoteOn(wr, key, 100); err != nil {
+					return err
+				}
+			}
+		}
+		wr.SetDelta(wr.Ticks4th())
+		return nil
+	})
+	fsys := fstest.MapFS{
+		"Set/1 Cmaj.mid": smfFile(layered),
+		"Set/2 Dmin.mid": smfFile(smfChord(t, 62, 65, 69, 62)),
+		"Set/3 Emin.mid": smfFile(smfChord(t, 64, 67, 71)),
+	}
+
+	var log strings.Builder
+	c := testConverter()
+	c.SetLogger(slog.New(slog.NewTextHandler(&log, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	sets := convertFS(t, &c, fsys)
+
+	if got := c.LastSummary().DuplicateNotes; got != 4 {
+		t.Errorf("DuplicateNotes = %d, want 4", got)
+	}
+	if got := sets[0].Chords[0].Notes; !slices.Equal(got, []int{0, 4, 7}) {
+		t.Errorf("chord 1 notes = %v, want [0 4 7]", got)
+	}
+	for _, want := range []string{`file="Set/1 Cmaj.mid" count=3`, `file="Set/2 Dmin.mid" count=1`} {
+		if !strings.Contains(log.String(), "duplicate notes collapsed") || !strings.Contains(log.String(), want) {
+			t.Errorf("log doesn't report duplicate notes of %s:\n%s", want, log.String())
+		}
+	}
+	if strings.Contains(log.String(), `file="Set/3 Emin.mid" count=`) {
+		t.Errorf("duplicate notes reported for a file without duplicates:\n%s", log.String())
+	}
+}
+
+func TestLenient(t *testing.T) {
+	// the chord without the last byte of the End of Track event
+	data := smfChord(t, 60, 64, 67)
+	truncated := fstest.MapFS{"Set/1 Cmaj.mid": smfFile(data[:len(data)-1])}
+
+	c := testConverter()
+	if _, err := c.ConvertFolder(truncated, "."); err == nil {
+		t.Error("ConvertFolder() of a truncated file without lenient mode: want an error")
+	}
+
+	var log strings.Builder
+	c = testConverter()
+	c.SetLogger(slog.New(slog.NewTextHandler(&log, nil)))
+	c.SetLenient(true)
+	sets := convertFS(t, &c, truncated)
+	if got := sets[0].Chords[0].Notes; !slices.Equal(got, []int{0, 4, 7}) {
+		t.Errorf("notes = %v, want [0 4 7]", got)
+	}
+	if want := `msg="MIDI file is corrupt, using the notes read before the error" file="Set/1 Cmaj.mid" notes=3`; !strings.Contains(log.String(), want) {
+		t.Errorf("log doesn't contain %s:\n%s", want, log.String())
+	}
+
+	// a file cut before any note is still an error
+	headerOnly := fstest.MapFS{"Set/1 Cmaj.mid": smfFile(data[:22])}
+	if _, err := c.ConvertFolder(headerOnly, "."); err == nil {
+		t.Error("ConvertFolder() of a file without notes in lenient mode: want an error")
+	}
+}
+
+func TestReadNotesAtTick(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		track []byte // delta, status, key, velocity; 96 ticks per quarter note
+		tick  int
+		want  []NoteEvent
+	}{
+		{
+			"released before the tick",
+			[]byte{0, 0x90, 60, 100, 0, 0x90, 64, 90, 10, 0x80, 60, 0},
+			20,
+			[]NoteEvent{{Note: 64, Velocity: 90}},
+		},
+		{
+			"released and replayed",
+			[]byte{0, 0x90, 60, 100, 10, 0x80, 60, 0, 5, 0x90, 60, 80},
+			20,
+			[]NoteEvent{{Note: 60, Velocity: 80, Tick: 15}},
+		},
+		{
+			"NoteOn with velocity 0 as release",
+			[]byte{0, 0x90, 60, 100, 0, 0x90, 64, 90, 10, 0x90, 60, 0},
+			20,
+			[]NoteEvent{{Note: 64, Velocity: 90}},
+		},
+		{
+			"NoteOff at the tick",
+			[]byte{0, 0x90, 60, 100, 0, 0x90, 64, 90, 20, 0x80, 60, 0, 20, 0x80, 64, 0},
+			20,
+			[]NoteEvent{{Note: 64, Velocity: 90}},
+		},
+		{
+			"note starting after the tick",
+			[]byte{0, 0x90, 60, 100, 30, 0x90, 64, 90},
+			20,
+			[]NoteEvent{{Note: 60, Velocity: 100}},
+		},
+		{
+			"note starting at the tick",
+			[]byte{0, 0x90, 60, 100, 20, 0x90, 64, 90},
+			20,
+			[]NoteEvent{{Note: 60, Velocity: 100}, {Note: 64, Velocity: 90, Tick: 20}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			notes, err := smfReader{channel: allChannels, tick: tt.tick}.ReadNotes(bytes.NewReader(rawSMF(0, tt.track)))
+			if err != nil {
+				t.Fatalf("ReadNotes() error = %v", err)
+			}
+			if !reflect.DeepEqual(notes, tt.want) {
+				t.Errorf("ReadNotes() = %+v, want %+v", notes, tt.want)
+			}
+		})
+	}
+}
+
+func TestReferenceTick(t *testing.T) {
+	// an arpeggio: C, E and G each start a quarter note after the previous one and are held to the end
+	fsys := fstest.MapFS{"Set/1 Cmaj.mid": smfFile(rawSMF(0, []byte{
+		0, 0x90, 60, 100, 96, 0x90, 64, 100, 96, 0x90, 67, 100, 96, 0x80, 60, 0, 0, 0x80, 64, 0, 0, 0x80, 67, 0,
+	}))}
+
+	for _, tt := range []struct {
+		tick int
+		want []int
+	}{
+		{-1, []int{0, 4, 7}},
+		{0, []int{0}},
+		{100, []int{0, 4}},
+		{192, []int{0, 4, 7}},
+		{288, []int{}},
+	} {
+		c := testConverter()
+		c.SetReferenceTick(tt.tick)
+		sets := convertFS(t, &c, fsys)
+		if got := sets[0].Chords[0].Notes; !slices.Equal(got, tt.want) {
+			t.Errorf("tick %d: notes = %v, want %v", tt.tick, got, tt.want)
+		}
+	}
+}
+
+func TestSMFFormats(t *testing.T) {
+	// the same chord: a bass note and a C major triad, held for a quarter note (96 ticks)
+	bass := []byte{0, 0x90, 48, 100, 96, 0x80, 48, 0}
+	triad := []byte{0, 0x90, 60, 100, 0, 0x90, 64, 100, 0, 0x90, 67, 100, 96, 0x80, 60, 0, 0, 0x80, 64, 0, 0, 0x80, 67, 0}
+	fixtures := []struct {
+		name string
+		data []byte
+	}{
+		// a single track with running status and NoteOns with velocity 0 as releases, as exported by many DAWs
+		{"type 0", rawSMF(0, []byte{0, 0x90, 48, 100, 0, 60, 100, 0, 64, 100, 0, 67, 100, 96, 48, 0, 0, 60, 0, 0, 64, 0, 0, 67, 0})},
+		{"type 0 without running status", rawSMF(0, slices.Concat(triad[:12], bass[:4], triad[12:], []byte{0, 0x80, 48, 0}))},
+		{"type 1", rawSMF(1, nil, bass, triad)},
+		{"type 2", rawSMF(2, bass, triad)},
+	}
+
+	for _, tick := range []int{-1, 0, 48} {
+		for _, f := range fixtures {
+			c := testConverter()
+			c.SetReferenceTick(tick)
+			sets := convertFS(t, &c, fstest.MapFS{"Set/1 Cmaj.mid": smfFile(f.data)})
+			if got, want := sets[0].Chords[0].Notes, []int{-12, 0, 4, 7}; !slices.Equal(got, want) {
+				t.Errorf("%s, tick %d: notes = %v, want %v", f.name, tick, got, want)
+			}
+		}
+	}
+}
+
+func TestOverlappingNotesAtTick(t *testing.T) {
+	// two tracks merged into one (type 0) both play C on the same channel: the first C is released
+	// before the reference tick, while the second one is still held
+	data := rawSMF(0, []byte{
+		0, 0x90, 60, 100, 0, 0x90, 64, 100, 0, 0x90, 60, 90, 0, 0x90, 67, 100,
+		10, 0x80, 60, 0,
+		86, 0x80, 60, 0, 0, 0x80, 64, 0, 0, 0x80, 67, 0,
+	})
+
+	for _, tt := range []struct {
+		tick int
+		want []int
+	}{
+		{5, []int{0, 4, 7}},
+		{48, []int{0, 4, 7}},
+		{96, []int{}},
+	} {
+		c := testConverter()
+		c.SetReferenceTick(tt.tick)
+		sets := convertFS(t, &c, fstest.MapFS{"Set/1 Cmaj.mid": smfFile(data)})
+		if got := sets[0].Chords[0].Notes; !slices.Equal(got, tt.want) {
+			t.Errorf("tick %d: notes = %v, want %v", tt.tick, got, tt.want)
+		}
+	}
+}
+
+func TestChordFromMIDI(t *testing.T) {
+	path := writeFile(t, "3  Cmaj7 .mid", string(smfChord(t, 71, 60, 67, 64)))
+
+	chord, err := ChordFromMIDI(path, 60)
+	if err != nil {
+		t.Fatalf("ChordFromMIDI() error = %v", err)
+	}
+	if chord.Name != "Cmaj7" || !slices.Equal(chord.Notes, []int{0, 4, 7, 11}) {
+		t.Errorf("ChordFromMIDI() = %+v, want Cmaj7 [0 4 7 11]", chord)
+	}
+
+	chord, err = ChordFromMIDI(path, 48)
+	if err != nil {
+		t.Fatalf("ChordFromMIDI() error = %v", err)
+	}
+	if !slices.Equal(chord.Notes, []int{12, 16, 19, 23}) {
+		t.Errorf("ChordFromMIDI() with base note 48 = %v, want [12 16 19 23]", chord.Notes)
+	}
+}
+
+func TestChordFromMIDIErrors(t *testing.T) {
+	empty := writeFile(t, "1 Empty.mid", string(smfData(t, func(wr *writer.SMF) error { return nil })))
+	if _, err := ChordFromMIDI(empty, 60); !errors.Is(err, ErrNoNotes) {
+		t.Errorf("ChordFromMIDI() of a file without notes error = %v, want ErrNoNotes", err)
+	}
+
+	for _, path := range []string{
+		writeFile(t, "Cmaj.mid", string(smfChord(t, 60, 64, 67))),
+		writeFile(t, "1 Cmaj.mid", "not a MIDI file"),
+		filepath.Join(t.TempDir(), "1 Missing.mid"),
+	} {
+		if _, err := ChordFromMIDI(path, 60); err == nil {
+			t.Errorf("ChordFromMIDI(%q): want an error", filepath.Base(path))
+		}
+	}
+}
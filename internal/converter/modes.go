@@ -0,0 +1,325 @@
+package converter
+
+import "fmt"
+
+// NoteRangePolicy defines how notes outside the allowed relative range are handled.
+type NoteRangePolicy int
+
+const (
+	NoteRangeIgnore NoteRangePolicy = iota // out of range notes are kept as is (default)
+	NoteRangeClamp                         // out of range notes are clamped to the nearest range bound
+	NoteRangeSkip                          // out of range notes are dropped
+	NoteRangeError                         // out of range notes cause an error
+	NoteRangeFold                          // out of range notes are moved by octaves into the range (see helpers.FoldNote)
+)
+
+// noteRangePolicyNames maps note range policies to their names used in the command line.
+var noteRangePolicyNames = map[NoteRangePolicy]string{
+	NoteRangeIgnore: "ignore",
+	NoteRangeClamp:  "clamp",
+	NoteRangeSkip:   "skip",
+	NoteRangeError:  "error",
+	NoteRangeFold:   "fold",
+}
+
+// String returns the name of the note range policy.
+func (p NoteRangePolicy) String() string {
+	if name, ok := noteRangePolicyNames[p]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("NoteRangePolicy(%d)", int(p))
+}
+
+// ParseNoteRangePolicy returns the note range policy with the given name.
+func ParseNoteRangePolicy(name string) (NoteRangePolicy, error) {
+	for p, n := range noteRangePolicyNames {
+		if n == name {
+			return p, nil
+		}
+	}
+
+	return NoteRangeIgnore, fmt.Errorf("unknown note range policy: %s", name)
+}
+
+// SetOrder defines the order in which set folders are processed and, consequently, numbered in output file names.
+type SetOrder int
+
+const (
+	SetOrderNameAsc  SetOrder = iota // by folder name, ascending (default)
+	SetOrderNameDesc                 // by folder name, descending
+	SetOrderModTime                  // by folder modification time, oldest first
+)
+
+// setOrderNames maps set orders to their names used in the command line.
+var setOrderNames = map[SetOrder]string{
+	SetOrderNameAsc:  "name-asc",
+	SetOrderNameDesc: "name-desc",
+	SetOrderModTime:  "mod-time",
+}
+
+// String returns the name of the set order.
+func (o SetOrder) String() string {
+	if name, ok := setOrderNames[o]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("SetOrder(%d)", int(o))
+}
+
+// ParseSetOrder returns the set order with the given name.
+func ParseSetOrder(name string) (SetOrder, error) {
+	for o, n := range setOrderNames {
+		if n == name {
+			return o, nil
+		}
+	}
+
+	return SetOrderNameAsc, fmt.Errorf("unknown set order: %s", name)
+}
+
+// NameSource defines where chord names are taken from.
+type NameSource int
+
+const (
+	NameSourceFilename         NameSource = iota // chord name from the file name (default)
+	NameSourceMetaText                           // chord name from the MIDI track name or text, falling back to the file name
+	NameSourceFilenameThenMeta                   // chord name from the file name, falling back to the MIDI track name or text
+)
+
+// nameSourceNames maps name sources to their names used in the command line.
+var nameSourceNames = map[NameSource]string{
+	NameSourceFilename:         "filename",
+	NameSourceMetaText:         "meta",
+	NameSourceFilenameThenMeta: "filename-then-meta",
+}
+
+// String returns the name of the name source.
+func (s NameSource) String() string {
+	if name, ok := nameSourceNames[s]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("NameSource(%d)", int(s))
+}
+
+// ParseNameSource returns the name source with the given name.
+func ParseNameSource(name string) (NameSource, error) {
+	for s, n := range nameSourceNames {
+		if n == name {
+			return s, nil
+		}
+	}
+
+	return NameSourceFilename, fmt.Errorf("unknown name source: %s", name)
+}
+
+// NoteMode defines how note values are written to the chord sets.
+type NoteMode int
+
+const (
+	NoteModeRelative NoteMode = iota // notes relative to the base note (default)
+	NoteModeAbsolute                 // raw MIDI note numbers
+)
+
+// noteModeNames maps note modes to their names used in the command line.
+var noteModeNames = map[NoteMode]string{
+	NoteModeRelative: "relative",
+	NoteModeAbsolute: "absolute",
+}
+
+// String returns the name of the note mode.
+func (m NoteMode) String() string {
+	if name, ok := noteModeNames[m]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("NoteMode(%d)", int(m))
+}
+
+// ParseNoteMode returns the note mode with the given name.
+func ParseNoteMode(name string) (NoteMode, error) {
+	for m, n := range noteModeNames {
+		if n == name {
+			return m, nil
+		}
+	}
+
+	return NoteModeRelative, fmt.Errorf("unknown note mode: %s", name)
+}
+
+// OutputFormat defines the format of the generated files.
+type OutputFormat int
+
+const (
+	OutputFormatJSON OutputFormat = iota // a JSON file per chord set (default)
+	OutputFormatCSV                      // a single CSV file with a row per chord
+)
+
+// outputFormatNames maps output formats to their names used in the command line.
+var outputFormatNames = map[OutputFormat]string{
+	OutputFormatJSON: "json",
+	OutputFormatCSV:  "csv",
+}
+
+// String returns the name of the output format.
+func (f OutputFormat) String() string {
+	if name, ok := outputFormatNames[f]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("OutputFormat(%d)", int(f))
+}
+
+// ParseOutputFormat returns the output format with the given name.
+func ParseOutputFormat(name string) (OutputFormat, error) {
+	for f, n := range outputFormatNames {
+		if n == name {
+			return f, nil
+		}
+	}
+
+	return OutputFormatJSON, fmt.Errorf("unknown output format: %s", name)
+}
+
+// NoteLimitPolicy defines how chords with more notes than the maximum are handled.
+type NoteLimitPolicy int
+
+const (
+	NoteLimitTruncate NoteLimitPolicy = iota // keep only the lowest notes (default)
+	NoteLimitWarn                            // keep all notes, but log a warning
+	NoteLimitError                           // fail the file
+)
+
+// noteLimitPolicyNames maps note limit policies to their names used in the command line.
+var noteLimitPolicyNames = map[NoteLimitPolicy]string{
+	NoteLimitTruncate: "truncate",
+	NoteLimitWarn:     "warn",
+	NoteLimitError:    "error",
+}
+
+// String returns the name of the note limit policy.
+func (p NoteLimitPolicy) String() string {
+	if name, ok := noteLimitPolicyNames[p]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("NoteLimitPolicy(%d)", int(p))
+}
+
+// ParseNoteLimitPolicy returns the note limit policy with the given name.
+func ParseNoteLimitPolicy(name string) (NoteLimitPolicy, error) {
+	for p, n := range noteLimitPolicyNames {
+		if n == name {
+			return p, nil
+		}
+	}
+
+	return NoteLimitTruncate, fmt.Errorf("unknown note limit policy: %s", name)
+}
+
+// NoteSort defines the order of notes in the chords.
+type NoteSort int
+
+const (
+	NoteSortAscending  NoteSort = iota // from the lowest to the highest note (default)
+	NoteSortDescending                 // from the highest to the lowest note
+	NoteSortAsPlayed                   // by the onset tick of each note
+)
+
+// noteSortNames maps note sort orders to their names used in the command line.
+var noteSortNames = map[NoteSort]string{
+	NoteSortAscending:  "ascending",
+	NoteSortDescending: "descending",
+	NoteSortAsPlayed:   "as-played",
+}
+
+// String returns the name of the note sort order.
+func (s NoteSort) String() string {
+	if name, ok := noteSortNames[s]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("NoteSort(%d)", int(s))
+}
+
+// ParseNoteSort returns the note sort order with the given name.
+func ParseNoteSort(name string) (NoteSort, error) {
+	for s, n := range noteSortNames {
+		if n == name {
+			return s, nil
+		}
+	}
+
+	return NoteSortAscending, fmt.Errorf("unknown note sort order: %s", name)
+}
+
+// SetConflict defines how set folders with the same path in several input folders are handled (see SetSetsFolders).
+type SetConflict int
+
+const (
+	SetConflictFirst SetConflict = iota // the set folder of the first input folder is used, the others are ignored (default)
+	SetConflictMerge                    // the files of all the set folders are combined into one set
+	SetConflictError                    // the run fails
+)
+
+// setConflictNames maps set conflict policies to their names used in the command line.
+var setConflictNames = map[SetConflict]string{
+	SetConflictFirst: "first",
+	SetConflictMerge: "merge",
+	SetConflictError: "error",
+}
+
+// String returns the name of the set conflict policy.
+func (p SetConflict) String() string {
+	if name, ok := setConflictNames[p]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("SetConflict(%d)", int(p))
+}
+
+// ParseSetConflict returns the set conflict policy with the given name.
+func ParseSetConflict(name string) (SetConflict, error) {
+	for p, n := range setConflictNames {
+		if n == name {
+			return p, nil
+		}
+	}
+
+	return SetConflictFirst, fmt.Errorf("unknown set conflict policy: %s", name)
+}
+
+// NumberingMode defines how chord numbers are assigned to the MIDI files of a set.
+type NumberingMode int
+
+const (
+	NumberingFromFilename   NumberingMode = iota // the number is parsed from the file name, files without it are errors (default)
+	NumberingAutoSequential                      // files without a number fill the free chord slots in sorted order
+)
+
+// numberingModeNames maps numbering modes to their names used in the command line.
+var numberingModeNames = map[NumberingMode]string{
+	NumberingFromFilename:   "filename",
+	NumberingAutoSequential: "auto",
+}
+
+// String returns the name of the numbering mode.
+func (m NumberingMode) String() string {
+	if name, ok := numberingModeNames[m]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("NumberingMode(%d)", int(m))
+}
+
+// ParseNumberingMode returns the numbering mode with the given name.
+func ParseNumberingMode(name string) (NumberingMode, error) {
+	for m, n := range numberingModeNames {
+		if n == name {
+			return m, nil
+		}
+	}
+
+	return NumberingFromFilename, fmt.Errorf("unknown numbering mode: %s", name)
+}
@@ -0,0 +1,124 @@
+package converter
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// multiFS is a read-only union of several OS folders. A directory present in several folders lists
+// the entries of all of them (the first folder wins for entries with the same name), and files are
+// read from the first folder that has them. Set folders can be pinned to a single folder, so that
+// they are read only from that folder.
+type multiFS struct {
+	roots  []string       // OS paths of the folders, in order of precedence
+	fss    []fs.FS        // file systems of the folders
+	pinned map[string]int // index of the folder from which each pinned set folder is read
+}
+
+// newMultiFS returns the union of the given OS folders.
+func newMultiFS(roots []string) *multiFS {
+	m := &multiFS{roots: roots, pinned: make(map[string]int)}
+	for _, root := range roots {
+		m.fss = append(m.fss, os.DirFS(root))
+	}
+
+	return m
+}
+
+// candidates returns the indexes of the folders in which name is looked up.
+func (m *multiFS) candidates(name string) []int {
+	for dir, i := range m.pinned {
+		if name == dir || strings.HasPrefix(name, dir+"/") {
+			return []int{i}
+		}
+	}
+
+	indexes := make([]int, len(m.fss))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	return indexes
+}
+
+// Open opens the named file from the first folder that has it.
+func (m *multiFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	for _, i := range m.candidates(name) {
+		f, err := m.fss[i].Open(name)
+		if !errors.Is(err, fs.ErrNotExist) {
+			return f, err
+		}
+	}
+
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir returns the entries of the named directory in all folders that have it, sorted by name.
+func (m *multiFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	seen := make(map[string]bool)
+	found := false
+
+	for _, i := range m.candidates(name) {
+		dir, err := fs.ReadDir(m.fss[i], name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		for _, e := range dir {
+			if !seen[e.Name()] {
+				seen[e.Name()] = true
+				entries = append(entries, e)
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+
+	return entries, nil
+}
+
+// dirRoots returns the indexes of the folders that contain the named directory.
+func (m *multiFS) dirRoots(name string) []int {
+	var indexes []int
+	for i, fsys := range m.fss {
+		if info, err := fs.Stat(fsys, name); err == nil && info.IsDir() {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes
+}
+
+// pin makes the named set folder and everything below it be read only from the folder with the given index.
+func (m *multiFS) pin(name string, index int) {
+	m.pinned[name] = index
+}
+
+// osPath returns the OS path of the named file or folder in the first folder that has it.
+func (m *multiFS) osPath(name string) string {
+	indexes := m.candidates(name)
+	root := m.roots[indexes[0]]
+	for _, i := range indexes {
+		if _, err := fs.Stat(m.fss[i], name); err == nil {
+			root = m.roots[i]
+			break
+		}
+	}
+
+	return filepath.Join(root, filepath.FromSlash(name))
+}
@@ -0,0 +1,78 @@
+package converter
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// mergeFixtures returns two input folders, both with a set folder named Shared.
+func mergeFixtures(t *testing.T) (string, string) {
+	t.Helper()
+
+	a := writeTree(t, fstest.MapFS{
+		"Alpha/1 Cmaj.mid":  smfFile(smfChord(t, 60, 64, 67)),
+		"Shared/1 Dmin.mid": smfFile(smfChord(t, 62, 65, 69)),
+	})
+	b := writeTree(t, fstest.MapFS{
+		"Beta/1 Emin.mid":   smfFile(smfChord(t, 64, 67, 71)),
+		"Shared/2 Fmaj.mid": smfFile(smfChord(t, 65, 69, 72)),
+	})
+
+	return a, b
+}
+
+func TestMultipleInputFolders(t *testing.T) {
+	a, b := mergeFixtures(t)
+
+	for _, tt := range []struct {
+		policy SetConflict
+		shared []string // chord names of the Shared set
+	}{
+		{SetConflictFirst, []string{"Dmin", "Chd 2"}},
+		{SetConflictMerge, []string{"Dmin", "Fmaj"}},
+	} {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			c := testConverter()
+			c.SetMaxChords(2)
+			c.SetSetConflict(tt.policy)
+			sets := convertFS(t, &c, newMultiFS([]string{a, b}))
+
+			if want := []string{"Alpha", "Beta", "Shared"}; !slices.Equal(setNames(sets), want) {
+				t.Fatalf("sets = %q, want %q", setNames(sets), want)
+			}
+			if got := chordNames(sets[2].Chords); !slices.Equal(got, tt.shared) {
+				t.Errorf("chords of Shared = %q, want %q", got, tt.shared)
+			}
+		})
+	}
+}
+
+func TestMultipleInputFoldersConflictError(t *testing.T) {
+	a, b := mergeFixtures(t)
+
+	c := testConverter()
+	c.SetSetConflict(SetConflictError)
+	_, err := c.ConvertFolder(newMultiFS([]string{a, b}), ".")
+	if err == nil || !strings.Contains(err.Error(), "set folder Shared is present in several input folders") {
+		t.Errorf("ConvertFolder() error = %v, want a set conflict", err)
+	}
+}
+
+func TestRunMultipleInputFolders(t *testing.T) {
+	a, b := mergeFixtures(t)
+	outDir := t.TempDir()
+
+	c := testConverter()
+	c.SetSetsFolders(a, b)
+	c.SetOutputFolder(outDir)
+	if err := c.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := []string{"user_chord_set_01.json", "user_chord_set_02.json", "user_chord_set_03.json"}
+	if got := dirNames(t, outDir); !slices.Equal(got, want) {
+		t.Errorf("output files = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package converter
+
+import "log/slog"
+
+// Option configures a Converter created with New. Each option applies the setter of the same name,
+// e.g. WithBaseNote(48) is equivalent to calling SetBaseNote(48) on the new converter.
+type Option func(c *Converter)
+
+// WithSetsFolder sets the path to the folder containing chord set directories (see SetSetsFolder).
+func WithSetsFolder(path string) Option {
+	return func(c *Converter) { c.SetSetsFolder(path) }
+}
+
+// WithOutputFolder sets the path to the folder where output files are written (see SetOutputFolder).
+func WithOutputFolder(path string) Option {
+	return func(c *Converter) { c.SetOutputFolder(path) }
+}
+
+// WithBaseNote sets the note relative to which the note values are calculated (see SetBaseNote).
+func WithBaseNote(n int) Option {
+	return func(c *Converter) { c.SetBaseNote(n) }
+}
+
+// WithMaxChords sets the number of chords in a set (see SetMaxChords).
+func WithMaxChords(n int) Option {
+	return func(c *Converter) { c.SetMaxChords(n) }
+}
+
+// WithMaxSets sets the maximum number of processed chord sets (see SetMaxSets).
+func WithMaxSets(n int) Option {
+	return func(c *Converter) { c.SetMaxSets(n) }
+}
+
+// WithNoteRange sets the allowed range of relative note values and the out of range policy (see SetNoteRange).
+func WithNoteRange(minNote, maxNote int, policy NoteRangePolicy) Option {
+	return func(c *Converter) { c.SetNoteRange(minNote, maxNote, policy) }
+}
+
+// WithNoteMode sets whether notes are written relative to the base note or as MIDI note numbers (see SetNoteMode).
+func WithNoteMode(mode NoteMode) Option {
+	return func(c *Converter) { c.SetNoteMode(mode) }
+}
+
+// WithOutputFormat sets the format of the generated files (see SetOutputFormat).
+func WithOutputFormat(format OutputFormat) Option {
+	return func(c *Converter) { c.SetOutputFormat(format) }
+}
+
+// WithWorkers sets the number of set folders processed concurrently (see SetWorkers).
+func WithWorkers(n int) Option {
+	return func(c *Converter) { c.SetWorkers(n) }
+}
+
+// WithLogger sets the logger for processing messages (see SetLogger).
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Converter) { c.SetLogger(logger) }
+}
+
+// WithMidiReader sets the reader of the chord notes (see SetMidiReader).
+func WithMidiReader(r MidiReader) Option {
+	return func(c *Converter) { c.SetMidiReader(r) }
+}
+
+// WithContinueOnError sets whether files that fail are skipped instead of aborting the set (see SetContinueOnError).
+func WithContinueOnError(continueOnError bool) Option {
+	return func(c *Converter) { c.SetContinueOnError(continueOnError) }
+}
+
+// WithDryRun sets whether the output files are only reported instead of written (see SetDryRun).
+func WithDryRun(dryRun bool) Option {
+	return func(c *Converter) { c.SetDryRun(dryRun) }
+}
@@ -0,0 +1,68 @@
+package converter
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	c := New(
+		WithSetsFolder("library"),
+		WithOutputFolder("out"),
+		WithBaseNote(48),
+		WithMaxChords(16),
+		WithMaxSets(4),
+		WithNoteRange(-24, 24, NoteRangeFold),
+		WithNoteMode(NoteModeAbsolute),
+		WithOutputFormat(OutputFormatCSV),
+		WithWorkers(2),
+		WithLogger(logger),
+		WithMidiReader(fakeReader{}),
+		WithContinueOnError(true),
+		WithDryRun(true),
+	)
+
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"setsFolder", c.setsFolder, "library"},
+		{"outputFolder", c.outputFolder, "out"},
+		{"baseNote", c.baseNote, 48},
+		{"maxChords", c.maxChords, 16},
+		{"maxSets", c.maxSets, 4},
+		{"note range", [3]any{c.minRelNote, c.maxRelNote, c.rangePolicy}, [3]any{-24, 24, NoteRangeFold}},
+		{"noteMode", c.noteMode, NoteModeAbsolute},
+		{"outputFormat", c.outputFormat, OutputFormatCSV},
+		{"workers", c.workers, 2},
+		{"logger", c.logger, logger},
+		{"reader", c.reader, MidiReader(fakeReader{})},
+		{"continueOnError", c.continueOnError, true},
+		{"dryRun", c.dryRun, true},
+		{"numberBase", c.numberBase, minChordNumber}, // not set by an option, keeps the default
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("%s = %v, want %v", check.name, check.got, check.want)
+		}
+	}
+
+	if err := c.validate(); err != nil {
+		t.Errorf("validate() error = %v", err)
+	}
+}
+
+func TestNewDefaults(t *testing.T) {
+	c := New()
+
+	if c.baseNote != defaultBaseNote || c.maxChords != defaultMaxChords || c.maxSets != defaultMaxSets {
+		t.Errorf("base note, max chords, max sets = %d, %d, %d, want the defaults", c.baseNote, c.maxChords, c.maxSets)
+	}
+	if c.outputTemplate != defaultOutputTemplate || c.fileMode != defaultFileMode {
+		t.Errorf("output template, file mode = %q, %v, want the defaults", c.outputTemplate, c.fileMode)
+	}
+	if err := c.validate(); err != nil {
+		t.Errorf("validate() error = %v", err)
+	}
+}
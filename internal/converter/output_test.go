@@ -0,0 +1,705 @@
+package converter
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestOutputFileNameTemplate(t *testing.T) {
+	c := testConverter()
+	c.SetOutputTemplate("{index} - {name}.json")
+
+	got, err := c.outputFileName(3, "Jazz: II")
+	if err != nil {
+		t.Fatalf("outputFileName() error = %v", err)
+	}
+	if want := "03 - Jazz_ II.json"; got != want {
+		t.Errorf("outputFileName() = %q, want %q", got, want)
+	}
+}
+
+func TestOutputFileNameInvalid(t *testing.T) {
+	c := testConverter()
+	c.SetOutputTemplate("{name}")
+
+	for _, name := range []string{"", " ", "..."} {
+		if got, err := c.outputFileName(1, name); err == nil {
+			t.Errorf("outputFileName(%q) = %q, want an error", name, got)
+		}
+	}
+}
+
+func TestOutputTemplateWithoutPlaceholder(t *testing.T) {
+	c := testConverter()
+	c.SetOutputTemplate("fixed.json")
+
+	if err := c.validate(); err == nil || !strings.Contains(err.Error(), "{index}") {
+		t.Errorf("validate() error = %v, want an error about the placeholders", err)
+	}
+}
+
+func TestOutputFileNameCollision(t *testing.T) {
+	outDir := t.TempDir()
+	c := testConverter()
+	c.SetOutputTemplate("{name}.json")
+	c.SetOutputFolder(outDir)
+	c.chordSets = []ChordSet{{Name: "a:b"}, {Name: "a?b"}}
+
+	err := c.outputFiles()
+	if err == nil || !strings.Contains(err.Error(), "a_b.json") {
+		t.Fatalf("outputFiles() error = %v, want a collision error", err)
+	}
+
+	entries, err := os.ReadDir(outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("%d files written, want none", len(entries))
+	}
+}
+
+func TestOutputFilesNamedBySet(t *testing.T) {
+	outDir := t.TempDir()
+	c := testConverter()
+	c.SetOutputTemplate("{index}_{name}.json")
+	c.SetOutputFolder(outDir)
+	c.chordSets = []ChordSet{{Name: "Piano"}, {Name: "Pads"}}
+
+	if err := c.outputFiles(); err != nil {
+		t.Fatalf("outputFiles() error = %v", err)
+	}
+
+	for _, name := range []string{"01_Piano.json", "02_Pads.json"} {
+		if _, err := os.Stat(filepath.Join(outDir, name)); err != nil {
+			t.Errorf("file %s not written: %v", name, err)
+		}
+	}
+}
+
+func TestCleanOutputRemovesStaleFiles(t *testing.T) {
+	root := writeTree(t, fstest.MapFS{
+		"A/1 C.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"B/1 D.mid": smfFile(smfChord(t, 62, 66, 69)),
+		"C/1 E.mid": smfFile(smfChord(t, 64, 68, 71)),
+	})
+	outDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outDir, "remap.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(clean bool) {
+		t.Helper()
+		c := testConverter()
+		c.SetSetsFolder(root)
+		c.SetOutputFolder(outDir)
+		c.SetCleanOutput(clean)
+		if err := c.Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	}
+
+	run(false)
+	if err := os.RemoveAll(filepath.Join(root, "C")); err != nil {
+		t.Fatal(err)
+	}
+
+	run(false) // the stale file is only reported
+	if _, err := os.Stat(filepath.Join(outDir, "user_chord_set_03.json")); err != nil {
+		t.Errorf("stale file removed without clean output: %v", err)
+	}
+
+	run(true)
+	if got, want := dirNames(t, outDir), []string{"remap.json", "user_chord_set_01.json", "user_chord_set_02.json"}; !slices.Equal(got, want) {
+		t.Errorf("output folder = %q, want %q", got, want)
+	}
+}
+
+func TestCleanOutputWithNameTemplate(t *testing.T) {
+	c := testConverter()
+	c.SetOutputTemplate("{name}.json")
+	c.SetCleanOutput(true)
+
+	if err := c.validate(); err == nil {
+		t.Error("validate() error = nil, want an error for -clean with {name}")
+	}
+}
+
+func TestStaleFilesIgnoredWithNameTemplate(t *testing.T) {
+	outDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outDir, "settings.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := testConverter()
+	c.SetOutputTemplate("{name}.json")
+	c.SetOutputFolder(outDir)
+	c.chordSets = []ChordSet{{Name: "Piano"}}
+	if err := c.outputFiles(); err != nil {
+		t.Fatalf("outputFiles() error = %v", err)
+	}
+
+	if got, want := dirNames(t, outDir), []string{"Piano.json", "settings.json"}; !slices.Equal(got, want) {
+		t.Errorf("output folder = %q, want %q", got, want)
+	}
+}
+
+func TestDryRunWritesNothing(t *testing.T) {
+	root := writeTree(t, fixtureTree(t, 2, 12))
+	outDir := filepath.Join(t.TempDir(), "out")
+
+	var log strings.Builder
+	c := testConverter()
+	c.SetLogger(slog.New(slog.NewTextHandler(&log, nil)))
+	c.SetSetsFolder(root)
+	c.SetOutputFolder(outDir)
+	c.SetManifest(true)
+	c.SetDryRun(true)
+	if err := c.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if _, err := os.Stat(outDir); !os.IsNotExist(err) {
+		t.Errorf("output folder created in dry-run mode: %v", err)
+	}
+	if got, want := dirNames(t, root), []string{"Set 01", "Set 02"}; !slices.Equal(got, want) {
+		t.Errorf("sets folder = %q, want %q", got, want)
+	}
+	if want := filepath.Join(outDir, "user_chord_set_02.json"); !strings.Contains(log.String(), want) {
+		t.Errorf("log doesn't report the target path %s:\n%s", want, log.String())
+	}
+}
+
+func TestOutputFileNamesZeroPadded(t *testing.T) {
+	c := testConverter()
+	c.chordSets = make([]ChordSet, defaultMaxSets)
+
+	got, err := c.outputFileNames()
+	if err != nil {
+		t.Fatalf("outputFileNames() error = %v", err)
+	}
+
+	want := []string{
+		"user_chord_set_01.json", "user_chord_set_02.json", "user_chord_set_03.json", "user_chord_set_04.json",
+		"user_chord_set_05.json", "user_chord_set_06.json", "user_chord_set_07.json", "user_chord_set_08.json",
+		"user_chord_set_09.json", "user_chord_set_10.json", "user_chord_set_11.json", "user_chord_set_12.json",
+		"user_chord_set_13.json", "user_chord_set_14.json", "user_chord_set_15.json", "user_chord_set_16.json",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("outputFileNames() = %q, want %q", got, want)
+	}
+}
+
+func TestOutputWriter(t *testing.T) {
+	root := writeTree(t, fixtureTree(t, 2, 12))
+
+	var buf bytes.Buffer
+	c := testConverter()
+	c.SetSetsFolder(root)
+	c.SetOutputWriter(&buf)
+	if err := c.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	var sets []ChordSet
+	if err := json.Unmarshal(buf.Bytes(), &sets); err != nil {
+		t.Fatalf("output is not a JSON array of chord sets: %v\n%s", err, buf.String())
+	}
+	if len(sets) != 2 || sets[0].Name != "Set 01" || sets[1].Name != "Set 02" {
+		t.Errorf("sets = %+v, want Set 01 and Set 02", sets)
+	}
+	if got, want := dirNames(t, root), []string{"Set 01", "Set 02"}; !slices.Equal(got, want) {
+		t.Errorf("sets folder = %q, want %q", got, want)
+	}
+}
+
+func TestCombinedFileMatchesSetFiles(t *testing.T) {
+	root := writeTree(t, fixtureTree(t, 3, 12))
+	outDir := t.TempDir()
+	combined := filepath.Join(t.TempDir(), "all.json")
+
+	c := testConverter()
+	c.SetSetsFolder(root)
+	c.SetOutputFolder(outDir)
+	c.SetCombinedFile(combined)
+	if err := c.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	data, err := os.ReadFile(combined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all []json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		t.Fatalf("combined file is not a JSON array: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("combined file has %d sets, want 3", len(all))
+	}
+
+	for i, raw := range all {
+		fileName := filepath.Join(outDir, fmt.Sprintf("user_chord_set_%02d.json", i+1))
+		set, err := LoadChordSet(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fromCombined ChordSet
+		if err := json.Unmarshal(raw, &fromCombined); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(fromCombined, set) {
+			t.Errorf("set %d in the combined file = %+v, want %+v", i+1, fromCombined, set)
+		}
+	}
+
+	marshaled, err := c.MarshalAll()
+	if err != nil {
+		t.Fatalf("MarshalAll() error = %v", err)
+	}
+	if !bytes.Equal(marshaled, data) {
+		t.Error("MarshalAll() differs from the combined file")
+	}
+}
+
+func TestSkipEmptySets(t *testing.T) {
+	root := writeTree(t, fstest.MapFS{
+		"Empty/readme.txt":  {Data: []byte("notes")},
+		"Empty/Cmaj.mid":    smfFile(smfChord(t, 60, 64, 67)),
+		"Piano/1 Cmaj.mid":  smfFile(smfChord(t, 60, 64, 67)),
+		"Strings/cover.png": {Data: []byte{0x89}},
+	})
+
+	for _, tt := range []struct {
+		skip bool
+		want []string
+	}{
+		{false, []string{"user_chord_set_01.json", "user_chord_set_02.json", "user_chord_set_03.json"}},
+		{true, []string{"user_chord_set_01.json"}},
+	} {
+		var log strings.Builder
+		outDir := t.TempDir()
+		c := testConverter()
+		c.SetLogger(slog.New(slog.NewTextHandler(&log, nil)))
+		c.SetSetsFolder(root)
+		c.SetOutputFolder(outDir)
+		c.SetContinueOnError(true)
+		c.SetSkipEmptySets(tt.skip)
+		if err := c.Run(); err == nil {
+			t.Fatalf("skip %v: Run() error = nil, want an error for Empty/Cmaj.mid", tt.skip)
+		}
+
+		if got := dirNames(t, outDir); !slices.Equal(got, tt.want) {
+			t.Errorf("skip %v: output files = %q, want %q", tt.skip, got, tt.want)
+		}
+		if got := strings.Count(log.String(), "no chords found in set folder"); got != 2 {
+			t.Errorf("skip %v: %d warnings about sets without chords, want 2:\n%s", tt.skip, got, log.String())
+		}
+	}
+}
+
+func TestTypeID(t *testing.T) {
+	root := writeTree(t, fstest.MapFS{"Piano/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67))})
+
+	for _, tt := range []struct {
+		typeID string
+		want   string
+		warn   bool
+	}{
+		{"", "native-instruments-chord-set", false},
+		{"my-chord-set", "my-chord-set", true},
+	} {
+		var log strings.Builder
+		outDir := t.TempDir()
+		c := testConverter()
+		c.SetLogger(slog.New(slog.NewTextHandler(&log, nil)))
+		c.SetSetsFolder(root)
+		c.SetOutputFolder(outDir)
+		c.SetTypeID(tt.typeID)
+		if err := c.Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+
+		set, err := LoadChordSet(filepath.Join(outDir, "user_chord_set_01.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if set.TypeID != tt.want {
+			t.Errorf("SetTypeID(%q): typeId = %q, want %q", tt.typeID, set.TypeID, tt.want)
+		}
+		if warned := strings.Contains(log.String(), "custom type id is not known"); warned != tt.warn {
+			t.Errorf("SetTypeID(%q): warning logged = %v, want %v", tt.typeID, warned, tt.warn)
+		}
+	}
+}
+
+func TestRunFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"library/Piano/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"library/Pads/2 Dmin.mid":  smfFile(smfChord(t, 62, 65, 69)),
+		"other/Strings/1 E.mid":    smfFile(smfChord(t, 64, 68, 71)),
+	}
+
+	c := testConverter()
+	if err := c.RunFS(fsys, "library"); err == nil {
+		t.Error("RunFS() without an output folder: error = nil, want an error")
+	}
+
+	outDir := t.TempDir()
+	c.SetOutputFolder(outDir)
+	if err := c.RunFS(fsys, "library"); err != nil {
+		t.Fatalf("RunFS() error = %v", err)
+	}
+
+	if got, want := dirNames(t, outDir), []string{"user_chord_set_01.json", "user_chord_set_02.json"}; !slices.Equal(got, want) {
+		t.Fatalf("output files = %q, want %q", got, want)
+	}
+	set, err := LoadChordSet(filepath.Join(outDir, "user_chord_set_01.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Name != "Pads" || set.Chords[1].Name != "Dmin" || !slices.Equal(set.Chords[1].Notes, []int{2, 5, 9}) {
+		t.Errorf("set 1 = %+v, want Pads with Dmin [2 5 9] as chord 2", set)
+	}
+}
+
+func TestRunContextCancel(t *testing.T) {
+	root := writeTree(t, fixtureTree(t, 5, 12))
+	outDir := t.TempDir()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := testConverter()
+	c.SetSetsFolder(root)
+	c.SetOutputFolder(outDir)
+	c.SetWorkers(1)
+	c.SetProgressFunc(func(done, total int, currentSet string) {
+		if done == 2 {
+			cancel()
+		}
+	})
+	err := c.RunContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("RunContext() error = %v, want %v", err, context.Canceled)
+	}
+
+	if got := dirNames(t, outDir); len(got) != 0 {
+		t.Errorf("output files = %q, want none after cancellation", got)
+	}
+	if got := c.LastSummary().SetsProcessed; got > 2 {
+		t.Errorf("SetsProcessed = %d, want at most the 2 sets processed before cancellation", got)
+	}
+
+	cancel()
+	if err := c.RunContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("RunContext() with a cancelled context: error = %v, want %v", err, context.Canceled)
+	}
+	if got := c.LastSummary().SetsProcessed; got != 0 {
+		t.Errorf("SetsProcessed = %d with a cancelled context, want 0", got)
+	}
+}
+
+func TestDebugSetsFolderName(t *testing.T) {
+	root := writeTree(t, fstest.MapFS{"chords/Piano/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67))})
+	t.Chdir(root)
+
+	c := testConverter()
+	c.SetDebug(true)
+	c.SetSetsFolderName("chords")
+	if err := c.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	set, err := LoadChordSet(filepath.Join(root, "user_chord_set_01.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Name != "Piano" {
+		t.Errorf("set name = %q, want Piano", set.Name)
+	}
+}
+
+// zipData returns a zip archive with the files of fsys.
+func zipData(t *testing.T, fsys fstest.MapFS) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range slices.Sorted(maps.Keys(fsys)) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(fsys[name].Data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestRunZip(t *testing.T) {
+	data := zipData(t, fstest.MapFS{
+		"Piano/1 Cmaj.mid":      smfFile(smfChord(t, 60, 64, 67)),
+		"Piano/2 Dmin.mid":      smfFile(smfChord(t, 62, 65, 69)),
+		"Pads/Warm/3 Emin.midi": smfFile(smfChord(t, 64, 67, 71)),
+		"readme.txt":            {Data: []byte("chord pack")},
+	})
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "pack.zip")
+	if err := os.WriteFile(zipPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := testConverter()
+	if err := c.RunZip(zipPath); err != nil {
+		t.Fatalf("RunZip() error = %v", err)
+	}
+
+	want := []string{"pack.zip", "user_chord_set_01.json", "user_chord_set_02.json"}
+	if got := dirNames(t, dir); !slices.Equal(got, want) {
+		t.Fatalf("folder = %q, want %q", got, want)
+	}
+	for i, want := range []struct {
+		name  string
+		chord int
+		notes []int
+	}{{"Pads", 3, []int{4, 7, 11}}, {"Piano", 2, []int{2, 5, 9}}} {
+		set, err := LoadChordSet(filepath.Join(dir, fmt.Sprintf("user_chord_set_%02d.json", i+1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := set.Chords[want.chord-1]; set.Name != want.name || !slices.Equal(got.Notes, want.notes) {
+			t.Errorf("set %d = %s with chord %d %v, want %s with %v", i+1, set.Name, want.chord, got.Notes, want.name, want.notes)
+		}
+	}
+}
+
+func TestOutputFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not supported on Windows")
+	}
+
+	outDir := t.TempDir()
+	c := testConverter()
+	c.SetOutputFolder(outDir)
+	c.SetOutputFileMode(0664)
+	c.chordSets = []ChordSet{{Name: "Piano"}}
+	if err := c.outputFiles(); err != nil {
+		t.Fatalf("outputFiles() error = %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(outDir, "user_chord_set_01.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != 0664 {
+		t.Errorf("file mode = %v, want %v", got, os.FileMode(0664))
+	}
+
+	c.SetOutputFileMode(os.ModeDir | 0755)
+	if err := c.validate(); err == nil {
+		t.Error("validate() error = nil, want an error for a mode with non-permission bits")
+	}
+}
+
+func TestRunStream(t *testing.T) {
+	root := writeTree(t, fixtureTree(t, 4, 12))
+
+	var names []string
+	c := testConverter()
+	c.SetSetsFolder(root)
+	if err := c.RunStream(func(set ChordSet) error {
+		names = append(names, set.Name)
+		return nil
+	}); err != nil {
+		t.Fatalf("RunStream() error = %v", err)
+	}
+	if want := []string{"Set 01", "Set 02", "Set 03", "Set 04"}; !slices.Equal(names, want) {
+		t.Errorf("callback sets = %q, want %q", names, want)
+	}
+	if got := dirNames(t, root); len(got) != 4 {
+		t.Errorf("sets folder = %q, want no output files", got)
+	}
+
+	stop := errors.New("stop")
+	names = nil
+	if err := c.RunStream(func(set ChordSet) error {
+		names = append(names, set.Name)
+		return stop
+	}); !errors.Is(err, stop) {
+		t.Errorf("RunStream() error = %v, want the callback error", err)
+	}
+	if len(names) != 1 {
+		t.Errorf("callback called %d times after an error, want 1", len(names))
+	}
+}
+
+func TestCompactJSON(t *testing.T) {
+	set := ChordSet{Name: "Piano", Chords: []Chord{{Name: "Cmaj", Notes: []int{0, 4, 7}}}, TypeID: "t", UUID: "u", Version: "1.0.0"}
+
+	write := func(compact bool) []byte {
+		t.Helper()
+		outDir := t.TempDir()
+		c := testConverter()
+		c.SetOutputFolder(outDir)
+		c.SetCompactJSON(compact)
+		c.chordSets = []ChordSet{set}
+		if err := c.outputFiles(); err != nil {
+			t.Fatalf("outputFiles() error = %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(outDir, "user_chord_set_01.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	pretty, compact := write(false), write(true)
+	if len(compact) >= len(pretty) {
+		t.Errorf("compact JSON has %d bytes, pretty JSON %d, want compact to be smaller", len(compact), len(pretty))
+	}
+	if !bytes.Contains(pretty, []byte("\n    \"chords\"")) {
+		t.Errorf("pretty JSON isn't indented with four spaces:\n%s", pretty)
+	}
+	if bytes.Contains(bytes.TrimSpace(compact), []byte("\n")) {
+		t.Errorf("compact JSON has several lines:\n%s", compact)
+	}
+
+	var fromPretty, fromCompact ChordSet
+	if err := json.Unmarshal(pretty, &fromPretty); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(compact, &fromCompact); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromPretty, fromCompact) {
+		t.Errorf("compact set = %+v, want %+v", fromCompact, fromPretty)
+	}
+}
+
+func TestContainedPath(t *testing.T) {
+	folder := t.TempDir()
+
+	for _, tt := range []struct {
+		fileName string
+		ok       bool
+	}{
+		{"user_chord_set_01.json", true},
+		{"sub/set.json", true},
+		{"..json", true},
+		{"../set.json", false},
+		{"../../etc/set.json", false},
+		{"sub/../../set.json", false},
+		{"..", false},
+		{".", false},
+	} {
+		path, err := containedPath(folder, tt.fileName)
+		if (err == nil) != tt.ok {
+			t.Errorf("containedPath(%q) error = %v, want ok = %v", tt.fileName, err, tt.ok)
+			continue
+		}
+		if tt.ok && path != filepath.Join(folder, tt.fileName) {
+			t.Errorf("containedPath(%q) = %q, want %q", tt.fileName, path, filepath.Join(folder, tt.fileName))
+		}
+	}
+}
+
+func TestOutputTraversal(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		template string
+		setName  string
+		want     []string // files in the output folder, nil if the run must fail
+	}{
+		{"set name", "{name}.json", "../../evil", []string{".._.._evil.json"}},
+		{"template", "../{index}.json", "Set", nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			outDir := filepath.Join(root, "out")
+
+			c := testConverter()
+			c.SetOutputFolder(outDir)
+			c.SetOutputTemplate(tt.template)
+			c.chordSets = []ChordSet{{Name: tt.setName, Chords: []Chord{{Name: "Cmaj", Notes: []int{0, 4, 7}}}}}
+			err := c.outputFiles()
+
+			if tt.want == nil {
+				if err == nil {
+					t.Error("outputFiles() error = nil, want an error")
+				}
+			} else if err != nil {
+				t.Fatalf("outputFiles() error = %v", err)
+			} else if got := dirNames(t, outDir); !slices.Equal(got, tt.want) {
+				t.Errorf("output files = %q, want %q", got, tt.want)
+			}
+			if got := dirNames(t, root); !slices.Equal(got, []string{"out"}) {
+				t.Errorf("files next to the output folder = %q, want only out", got)
+			}
+		})
+	}
+}
+
+func TestOutputFilesNotReadAsInput(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		template   string
+		extensions []string
+		want       []string // files of the set folder after the runs
+	}{
+		{"JSON read as MIDI", "", []string{".mid", ".json"}, []string{"1 Cmaj.mid", "2 Dmin.mid", "index.json", "user_chord_set_01.json"}},
+		{"MIDI file names", "{index} Out.mid", nil, []string{"01 Out.mid", "1 Cmaj.mid", "2 Dmin.mid", "index.json"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeTree(t, fstest.MapFS{
+				"Set/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+				"Set/2 Dmin.mid": smfFile(smfChord(t, 62, 65, 69)),
+			})
+
+			for run := range 2 {
+				c := testConverter()
+				c.SetSetsFolder(root)
+				// the output files are written into the set folder itself
+				c.SetOutputFolder(filepath.Join(root, "Set"))
+				c.SetMaxChords(2)
+				c.SetManifest(true)
+				if tt.template != "" {
+					c.SetOutputTemplate(tt.template)
+				}
+				if tt.extensions != nil {
+					c.SetMidiExtensions(tt.extensions...)
+				}
+				if err := c.Run(); err != nil {
+					t.Fatalf("run %d: Run() error = %v", run+1, err)
+				}
+
+				report := c.LastReport()
+				if len(report.Issues) != 0 || report.Summary.ChordsPopulated != 2 {
+					t.Errorf("run %d: report = %+v, want 2 chords and no issues", run+1, report)
+				}
+			}
+			if got := dirNames(t, filepath.Join(root, "Set")); !slices.Equal(got, tt.want) {
+				t.Errorf("files of the set folder = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// LoadNoteRemap reads a note remap table from a JSON file. The file contains an object that maps source note values
+// to target note values, e.g. {"0": 12, "4": 16}. The values are relative to the base note, or MIDI note numbers
+// in absolute note mode, as in the chord sets.
+func LoadNoteRemap(path string) (map[int]int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading remap file %s: %w", path, err)
+	}
+
+	var table map[string]int
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("error parsing remap file %s: %w", path, err)
+	}
+
+	remap := make(map[int]int, len(table))
+	for key, target := range table {
+		source, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source note %q in remap file %s: must be an integer", key, path)
+		}
+		remap[source] = target
+	}
+
+	return remap, nil
+}
+
+// validateRemap checks that all notes of the remap table map to valid MIDI notes in the note mode.
+func (c *Converter) validateRemap() error {
+	lo, hi := minMidiNote-c.noteOffset(c.baseNote), maxMidiNote-c.noteOffset(c.baseNote)
+	for source, target := range c.remap {
+		if target < lo || target > hi {
+			return fmt.Errorf("invalid remap of note %d to %d: must be in range %d..%d", source, target, lo, hi)
+		}
+	}
+
+	return nil
+}
+
+// remapNotes replaces the note values found in the remap table. Notes mapped onto the same value are kept once.
+// In strict mode, notes missing from the table are reported with a warning; they are kept unchanged.
+func (c *Converter) remapNotes(fileName string, notes []midiNote) []midiNote {
+	result := make([]midiNote, 0, len(notes))
+	for _, note := range notes {
+		target, ok := c.remap[note.value]
+		if !ok {
+			if c.strictRemap {
+				c.logger.Warn("note not found in remap table, kept", "file", fileName, "note", note.value)
+			}
+			result = appendUniqueNote(result, note)
+			continue
+		}
+		note.value = target
+		result = appendUniqueNote(result, note)
+	}
+
+	return result
+}
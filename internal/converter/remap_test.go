@@ -0,0 +1,68 @@
+package converter
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadNoteRemap(t *testing.T) {
+	remap, err := LoadNoteRemap(writeFile(t, "map.json", `{"0": 12, "4": 16}`))
+	if err != nil {
+		t.Fatalf("LoadNoteRemap() error = %v", err)
+	}
+	if want := map[int]int{0: 12, 4: 16}; !maps.Equal(remap, want) {
+		t.Errorf("LoadNoteRemap() = %v, want %v", remap, want)
+	}
+
+	for _, data := range []string{`{"C": 12}`, `[0, 12]`, `{"0": "12"}`} {
+		if _, err := LoadNoteRemap(writeFile(t, "map.json", data)); err == nil {
+			t.Errorf("LoadNoteRemap(%s): want an error", data)
+		}
+	}
+}
+
+func TestNoteRemap(t *testing.T) {
+	fsys := fstest.MapFS{"Set/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67))}
+
+	for _, tt := range []struct {
+		name   string
+		remap  map[int]int
+		strict bool
+		want   []int
+		warned bool
+	}{
+		{"transposition", map[int]int{0: 12, 4: 16, 7: 19}, false, []int{12, 16, 19}, false},
+		{"partial", map[int]int{0: 12}, false, []int{4, 7, 12}, false},
+		{"partial strict", map[int]int{0: 12}, true, []int{4, 7, 12}, true},
+		{"merged", map[int]int{0: 7}, false, []int{4, 7}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var log strings.Builder
+			c := testConverter()
+			c.SetLogger(slog.New(slog.NewTextHandler(&log, nil)))
+			c.SetNoteRemap(tt.remap, tt.strict)
+			sets := convertFS(t, &c, fsys)
+
+			if got := sets[0].Chords[0].Notes; !slices.Equal(got, tt.want) {
+				t.Errorf("notes = %v, want %v", got, tt.want)
+			}
+			if warned := strings.Contains(log.String(), "note not found in remap table"); warned != tt.warned {
+				t.Errorf("unmapped notes warned = %v, want %v:\n%s", warned, tt.warned, log.String())
+			}
+		})
+	}
+}
+
+func TestNoteRemapInvalidTarget(t *testing.T) {
+	fsys := fstest.MapFS{"Set/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67))}
+
+	c := testConverter()
+	c.SetNoteRemap(map[int]int{0: 200}, false)
+	if _, err := c.ConvertFolder(fsys, "."); err == nil {
+		t.Error("ConvertFolder() with a remap target out of range: want an error")
+	}
+}
@@ -0,0 +1,63 @@
+package converter
+
+import "fmt"
+
+// IssueKind defines the kind of an issue found during a conversion run.
+type IssueKind int
+
+const (
+	IssueFileError   IssueKind = iota // a file failed to parse or read
+	IssueFileSkipped                  // a file was skipped, e.g. it has no notes or its chord number is out of range
+	IssueCollision                    // a chord number is used by several files, or set folders have the same name
+	IssueEmptyChords                  // a set has chord slots without files
+	IssueSetSkipped                   // a set folder was not converted, e.g. it has no chords or exceeds the maximum
+)
+
+// issueKindNames maps issue kinds to their names used in reports.
+var issueKindNames = map[IssueKind]string{
+	IssueFileError:   "file-error",
+	IssueFileSkipped: "file-skipped",
+	IssueCollision:   "collision",
+	IssueEmptyChords: "empty-chords",
+	IssueSetSkipped:  "set-skipped",
+}
+
+// String returns the name of the issue kind.
+func (k IssueKind) String() string {
+	if name, ok := issueKindNames[k]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("IssueKind(%d)", int(k))
+}
+
+// MarshalText encodes the issue kind as its name, e.g. in JSON reports.
+func (k IssueKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Issue is a problem found during a conversion run.
+type Issue struct {
+	Kind    IssueKind `json:"kind"`           // kind of the issue
+	Set     string    `json:"set,omitempty"`  // name of the affected set, if any
+	File    string    `json:"file,omitempty"` // path of the affected file or folder, if any
+	Message string    `json:"message"`        // description of the issue
+}
+
+// Report is a machine-readable account of a conversion run: its statistics and all issues found,
+// e.g. for checks in CI pipelines.
+type Report struct {
+	Summary Summary `json:"summary"` // statistics of the run
+	Issues  []Issue `json:"issues"`  // issues in the order in which the sets were processed
+}
+
+// LastReport returns the report of the last run. If the run failed, the report covers the issues found
+// up to the failure, including the issue that caused it.
+func (c *Converter) LastReport() Report {
+	issues := c.issues
+	if issues == nil {
+		issues = []Issue{}
+	}
+
+	return Report{Summary: c.summary, Issues: issues}
+}
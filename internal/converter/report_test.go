@@ -0,0 +1,66 @@
+package converter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLastReport(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Set/1 Cmaj.mid":    smfFile(smfChord(t, 60, 64, 67)),
+		"Set/2 Broken.mid":  smfFile([]byte("not a MIDI file")),
+		"Set/3 Gmaj.mid":    smfFile(smfChord(t, 67, 71, 74)),
+		"Set/5 Amin.mid":    smfFile(smfChord(t, 69, 72, 76)),
+		"Other/1 Dmin7.mid": smfFile(smfChord(t, 62, 65, 69, 72)),
+	}
+
+	c := testConverter()
+	c.SetContinueOnError(true)
+	c.SetMaxChords(5)
+	if _, err := c.ConvertFolder(fsys, "."); err == nil {
+		t.Fatal("ConvertFolder() error = nil, want the error of the broken file")
+	}
+
+	report := c.LastReport()
+	kinds := make(map[IssueKind][]Issue)
+	for _, issue := range report.Issues {
+		kinds[issue.Kind] = append(kinds[issue.Kind], issue)
+	}
+
+	errs := kinds[IssueFileError]
+	if len(errs) != 1 {
+		t.Fatalf("file errors = %+v, want one", errs)
+	}
+	if got := errs[0]; got.Set != "Set" || got.File != "Set/2 Broken.mid" || got.Message == "" {
+		t.Errorf("file error = %+v, want the error of Set/2 Broken.mid", got)
+	}
+	if got := kinds[IssueEmptyChords]; len(got) == 0 {
+		t.Errorf("issues = %+v, want empty chords reported", report.Issues)
+	}
+	if report.Summary.FilesWithErrors != 1 {
+		t.Errorf("Summary.FilesWithErrors = %d, want 1", report.Summary.FilesWithErrors)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"kind":"file-error"`; !strings.Contains(string(data), want) {
+		t.Errorf("JSON report doesn't contain %s:\n%s", want, data)
+	}
+}
+
+func TestLastReportWithoutIssues(t *testing.T) {
+	c := testConverter()
+	convertFS(t, &c, fixtureTree(t, 1, 12))
+
+	data, err := json.Marshal(c.LastReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"issues":[]`; !strings.Contains(string(data), want) {
+		t.Errorf("JSON report doesn't contain %s:\n%s", want, data)
+	}
+}
@@ -0,0 +1,63 @@
+package converter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// schema describes the chord set JSON format of a specific version.
+type schema struct {
+	typeID string                 // value of the typeId field
+	layout func(set ChordSet) any // returns the value encoded as the chord set JSON; nil encodes the ChordSet as is
+}
+
+// schemas lists the supported chord set JSON format versions. A version whose field layout differs from ChordSet
+// provides a layout function that migrates the set to it.
+var schemas = map[string]schema{
+	"1.0.0": {typeID: "native-instruments-chord-set"},
+}
+
+// encodedSet returns the value encoded as the JSON of the chord set, in the layout of the schema version of the set.
+// Sets of unknown versions (e.g. loaded with LoadChordSet) are encoded as is.
+func encodedSet(set ChordSet) any {
+	if s, ok := schemas[set.Version]; ok && s.layout != nil {
+		return s.layout(set)
+	}
+
+	return set
+}
+
+// SupportedSchemaVersions returns the sorted list of supported chord set JSON format versions.
+func SupportedSchemaVersions() []string {
+	versions := make([]string, 0, len(schemas))
+	for v := range schemas {
+		versions = append(versions, v)
+	}
+	slices.Sort(versions)
+
+	return versions
+}
+
+// lookupSchema returns the schema of the given version or an error if the version is not supported.
+func lookupSchema(version string) (schema, error) {
+	s, ok := schemas[version]
+	if !ok {
+		return schema{}, fmt.Errorf(
+			"unsupported schema version %s (supported: %s)", version, strings.Join(SupportedSchemaVersions(), ", "),
+		)
+	}
+
+	return s, nil
+}
+
+// isKnownTypeID reports whether the type id is used by one of the supported schema versions.
+func isKnownTypeID(typeID string) bool {
+	for _, s := range schemas {
+		if s.typeID == typeID {
+			return true
+		}
+	}
+
+	return false
+}
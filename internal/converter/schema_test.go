@@ -0,0 +1,73 @@
+package converter
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestUnknownSchemaVersion(t *testing.T) {
+	c := testConverter()
+	c.SetSchemaVersion("9.9.9")
+	_, err := c.ConvertFolder(fstest.MapFS{"Set/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67))}, ".")
+	if err == nil || !strings.Contains(err.Error(), "unsupported schema version 9.9.9 (supported: 1.0.0)") {
+		t.Errorf("ConvertFolder() error = %v, want an unsupported schema version", err)
+	}
+
+	set := validSet(t)
+	set.Version = "9.9.9"
+	errs := ValidateChordSet(set)
+	if !slices.ContainsFunc(errs, func(err error) bool { return strings.Contains(err.Error(), "unsupported schema version 9.9.9") }) {
+		t.Errorf("ValidateChordSet() = %v, want an unsupported schema version", errs)
+	}
+}
+
+func TestSchemaLayout(t *testing.T) {
+	// a hypothetical version that renames the chords field and drops the UUID
+	schemas["2.0.0"] = schema{typeID: "chord-set-v2", layout: func(set ChordSet) any {
+		return struct {
+			Name   string  `json:"name"`
+			Pads   []Chord `json:"pads"`
+			TypeID string  `json:"typeId"`
+		}{set.Name, set.Chords, set.TypeID}
+	}}
+	t.Cleanup(func() { delete(schemas, "2.0.0") })
+
+	outDir := t.TempDir()
+	root := writeTree(t, fstest.MapFS{"Set/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67))})
+	c := testConverter()
+	c.SetSetsFolder(root)
+	c.SetOutputFolder(outDir)
+	c.SetSchemaVersion("2.0.0")
+	if err := c.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "user_chord_set_01.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := slices.Sorted(maps.Keys(fields)), []string{"name", "pads", "typeId"}; !slices.Equal(got, want) {
+		t.Errorf("fields = %q, want %q", got, want)
+	}
+	if got := string(fields["typeId"]); got != `"chord-set-v2"` {
+		t.Errorf("typeId = %s, want \"chord-set-v2\"", got)
+	}
+
+	all, err := c.MarshalAll()
+	if err != nil {
+		t.Fatalf("MarshalAll() error = %v", err)
+	}
+	if !strings.Contains(string(all), `"pads"`) || strings.Contains(string(all), `"uuid"`) {
+		t.Errorf("MarshalAll() doesn't use the layout of the version:\n%s", all)
+	}
+}
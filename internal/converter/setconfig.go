@@ -0,0 +1,55 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// setConfigFileName is the name of the optional configuration file in a set folder.
+const setConfigFileName = "set.json"
+
+// FolderConfig is the optional configuration of a single set, read from the set.json file in its set folder,
+// e.g. {"name": "Jazz", "baseNote": 48}. The fields that are present override the global configuration for the set.
+type FolderConfig struct {
+	Name     string `json:"name,omitempty"`     // set name instead of the folder name
+	TypeID   string `json:"typeId,omitempty"`   // value of the typeId field of the set
+	BaseNote *int   `json:"baseNote,omitempty"` // MIDI note relative to which the note values of the set are calculated
+}
+
+// readFolderConfig reads the set.json file of the set folder. If the folder has no such file, ok is false.
+// Unknown fields are errors, so that misspelled settings are not silently ignored.
+func (c *Converter) readFolderConfig(folder string) (config FolderConfig, ok bool, err error) {
+	configPath := path.Join(folder, setConfigFileName)
+
+	data, err := fs.ReadFile(c.fsys, configPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return FolderConfig{}, false, nil
+	}
+	if err != nil {
+		return FolderConfig{}, false, fmt.Errorf("error reading set config %s: %w", c.displayPath(configPath), err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return FolderConfig{}, false, fmt.Errorf("error parsing set config %s: %w", c.displayPath(configPath), err)
+	}
+
+	config.Name = strings.TrimSpace(config.Name)
+	if config.BaseNote != nil && (*config.BaseNote < minMidiNote || *config.BaseNote > maxMidiNote) {
+		return FolderConfig{}, false, fmt.Errorf("invalid base note %d in set config %s: must be in range %d..%d",
+			*config.BaseNote, c.displayPath(configPath), minMidiNote, maxMidiNote)
+	}
+
+	return config, true, nil
+}
+
+// isFolderConfig reports whether the file is the set.json file of the set folder.
+func isFolderConfig(folder, filePath string) bool {
+	return filePath == path.Join(folder, setConfigFileName)
+}
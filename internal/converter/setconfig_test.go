@@ -0,0 +1,91 @@
+package converter
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFolderConfig(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Jazz/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+		"Jazz/set.json":   &fstest.MapFile{Data: []byte(`{"name": " Smooth Jazz ", "typeId": "custom", "baseNote": 48}`)},
+		"Pop/1 Cmaj.mid":  smfFile(smfChord(t, 60, 64, 67)),
+	}
+
+	c := testConverter()
+	c.SetMaxChords(1)
+	sets := convertFS(t, &c, fsys)
+
+	if got, want := setNames(sets), []string{"Smooth Jazz", "Pop"}; !slices.Equal(got, want) {
+		t.Fatalf("sets = %q, want %q", got, want)
+	}
+	if got := sets[0].TypeID; got != "custom" {
+		t.Errorf("TypeID = %q, want custom", got)
+	}
+	if got := sets[0].Chords[0].Notes; !slices.Equal(got, []int{12, 16, 19}) {
+		t.Errorf("notes relative to base note 48 = %v, want [12 16 19]", got)
+	}
+	if got := sets[1].Chords[0].Notes; !slices.Equal(got, []int{0, 4, 7}) {
+		t.Errorf("notes of the set without config = %v, want [0 4 7]", got)
+	}
+	if issues := c.LastReport().Issues; len(issues) != 0 {
+		t.Errorf("issues = %+v, want none for set.json", issues)
+	}
+}
+
+func TestFolderConfigInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data string
+	}{
+		{"syntax", `{"name": `},
+		{"unknown field", `{"nmae": "Jazz"}`},
+		{"base note", `{"baseNote": 128}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{
+				"Jazz/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+				"Jazz/set.json":   &fstest.MapFile{Data: []byte(tt.data)},
+			}
+
+			c := testConverter()
+			if _, err := c.ConvertFolder(fsys, "."); err == nil {
+				t.Errorf("ConvertFolder() with set.json %s: want an error", tt.data)
+			}
+		})
+	}
+}
+
+func TestFolderConfigConcurrent(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for i := range 8 {
+		dir := fmt.Sprintf("Set %d", i+1)
+		fsys[dir+"/1 Cmaj.mid"] = smfFile(smfChord(t, 60, 64, 67))
+		if i%2 == 0 {
+			fsys[dir+"/set.json"] = &fstest.MapFile{Data: []byte(`{"baseNote": 48}`)}
+		}
+	}
+
+	c := testConverter()
+	c.SetMaxChords(1)
+	c.SetWorkers(4)
+	sets := convertFS(t, &c, fsys)
+
+	if len(sets) != 8 {
+		t.Fatalf("got %d sets, want 8", len(sets))
+	}
+	for i, set := range sets {
+		want := []int{0, 4, 7}
+		if i%2 == 0 {
+			want = []int{12, 16, 19}
+		}
+		if got := set.Chords[0].Notes; !slices.Equal(got, want) {
+			t.Errorf("notes of %s = %v, want %v", set.Name, got, want)
+		}
+	}
+	if c.baseNote != defaultBaseNote {
+		t.Errorf("base note after the run = %d, want %d", c.baseNote, defaultBaseNote)
+	}
+}
@@ -0,0 +1,67 @@
+package converter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Summary holds aggregate statistics of a conversion run.
+type Summary struct {
+	SetsProcessed   int `json:"setsProcessed"`   // number of processed chord sets
+	ChordsPopulated int `json:"chordsPopulated"` // number of chords filled from MIDI files
+	ChordsEmpty     int `json:"chordsEmpty"`     // number of chords left as empty placeholders
+	FilesSkipped    int `json:"filesSkipped"`    // number of files skipped (not MIDI or out of range chord number)
+	FilesWithErrors int `json:"filesWithErrors"` // number of files that failed to parse or read
+	DuplicateNotes  int `json:"duplicateNotes"`  // number of duplicate NoteOns of the same notes collapsed (e.g. doubled on several channels)
+
+	EmptySlots []SetEmptySlots `json:"emptySlots,omitempty"` // empty chord slots of the processed sets that have any
+}
+
+// SetEmptySlots lists the chord slots of a set that were not filled from MIDI files.
+type SetEmptySlots struct {
+	Set   string `json:"set"`   // set name
+	Slots []int  `json:"slots"` // numbers of the empty chord slots (starting from 1), in ascending order
+}
+
+// add adds the statistics of other to the summary.
+func (s *Summary) add(other Summary) {
+	s.SetsProcessed += other.SetsProcessed
+	s.ChordsPopulated += other.ChordsPopulated
+	s.ChordsEmpty += other.ChordsEmpty
+	s.FilesSkipped += other.FilesSkipped
+	s.FilesWithErrors += other.FilesWithErrors
+	s.DuplicateNotes += other.DuplicateNotes
+	s.EmptySlots = append(s.EmptySlots, other.EmptySlots...)
+}
+
+// String returns a human-readable representation of the summary, followed by the number of collapsed duplicate notes
+// (if any) and a line per set with empty chord slots.
+func (s Summary) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb,
+		"summary: %d sets processed, %d chords populated, %d chords empty, %d files skipped, %d files with errors",
+		s.SetsProcessed, s.ChordsPopulated, s.ChordsEmpty, s.FilesSkipped, s.FilesWithErrors,
+	)
+
+	if s.DuplicateNotes > 0 {
+		fmt.Fprintf(&sb, "\n  duplicate notes collapsed: %d", s.DuplicateNotes)
+	}
+
+	for _, e := range s.EmptySlots {
+		fmt.Fprintf(&sb, "\n  empty slots in %s: %s", e.Set, joinNumbers(e.Slots))
+	}
+
+	return sb.String()
+}
+
+// joinNumbers returns the numbers separated by commas, e.g. "1, 3, 5".
+func joinNumbers(numbers []int) string {
+	s := make([]string, 0, len(numbers))
+	for _, n := range numbers {
+		s = append(s, strconv.Itoa(n))
+	}
+
+	return strings.Join(s, ", ")
+}
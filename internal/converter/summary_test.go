@@ -0,0 +1,34 @@
+package converter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmptySlots(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for n := 1; n <= defaultMaxChords; n++ {
+		fsys[fmt.Sprintf("Full/%d C.mid", n)] = smfFile(smfChord(t, 60, 64, 67))
+		if n != 4 && n != 9 {
+			fsys[fmt.Sprintf("Gaps/%d C.mid", n)] = smfFile(smfChord(t, 60, 64, 67))
+		}
+	}
+
+	c := testConverter()
+	convertFS(t, &c, fsys)
+	summary := c.LastSummary()
+
+	want := []SetEmptySlots{{Set: "Gaps", Slots: []int{4, 9}}}
+	if !reflect.DeepEqual(summary.EmptySlots, want) {
+		t.Errorf("EmptySlots = %+v, want %+v", summary.EmptySlots, want)
+	}
+	if summary.ChordsPopulated != 22 || summary.ChordsEmpty != 2 {
+		t.Errorf("chords populated/empty = %d/%d, want 22/2", summary.ChordsPopulated, summary.ChordsEmpty)
+	}
+	if s := summary.String(); !strings.Contains(s, "\n  empty slots in Gaps: 4, 9") || strings.Contains(s, "slots in Full") {
+		t.Errorf("String() = %q, want a line with the empty slots of Gaps only", s)
+	}
+}
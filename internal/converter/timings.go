@@ -0,0 +1,20 @@
+package converter
+
+import "time"
+
+// Timings holds the wall-clock durations of the last run, measured with the clock set with SetClock.
+type Timings struct {
+	Total time.Duration // duration of the whole run, including writing the output files
+	Sets  []SetTiming   // durations of the processed set folders, in the order of the sets
+}
+
+// SetTiming is the duration of processing a single set folder.
+type SetTiming struct {
+	Set      string        // set name
+	Duration time.Duration // time spent reading the files of the set folder and building the chord set
+}
+
+// LastTimings returns the durations of the last run, e.g. to find set folders that are slow to process.
+func (c *Converter) LastTimings() Timings {
+	return c.timings
+}
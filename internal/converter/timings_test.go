@@ -0,0 +1,70 @@
+package converter
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stepClock returns a clock that advances by the step on every call.
+func stepClock(step time.Duration) Clock {
+	var mu sync.Mutex
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	return func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(step)
+		return now
+	}
+}
+
+func TestLastTimings(t *testing.T) {
+	var log strings.Builder
+	c := testConverter()
+	c.SetLogger(slog.New(slog.NewTextHandler(&log, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	c.SetClock(stepClock(time.Second))
+	c.SetWorkers(1)
+	convertFS(t, &c, fixtureTree(t, 3, 4))
+
+	timings := c.LastTimings()
+	var sets []string
+	var sum time.Duration
+	for _, st := range timings.Sets {
+		sets = append(sets, st.Set)
+		if st.Duration != time.Second {
+			t.Errorf("duration of %s = %v, want 1s", st.Set, st.Duration)
+		}
+		sum += st.Duration
+	}
+	if want := []string{"Set 01", "Set 02", "Set 03"}; !slices.Equal(sets, want) {
+		t.Errorf("timed sets = %q, want %q", sets, want)
+	}
+	if timings.Total < sum {
+		t.Errorf("Total = %v, want at least the sum of the sets %v", timings.Total, sum)
+	}
+	if want := `msg="set processed" name="Set 01" duration=1s`; !strings.Contains(log.String(), want) {
+		t.Errorf("log doesn't contain %s:\n%s", want, log.String())
+	}
+}
+
+func TestLastTimingsRealClock(t *testing.T) {
+	c := testConverter()
+	convertFS(t, &c, fixtureTree(t, 2, 4))
+
+	timings := c.LastTimings()
+	if len(timings.Sets) != 2 {
+		t.Fatalf("got %d set timings, want 2", len(timings.Sets))
+	}
+	for _, st := range timings.Sets {
+		if st.Duration < 0 {
+			t.Errorf("duration of %s = %v, want non-negative", st.Set, st.Duration)
+		}
+	}
+	if timings.Total < 0 {
+		t.Errorf("Total = %v, want non-negative", timings.Total)
+	}
+}
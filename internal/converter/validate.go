@@ -0,0 +1,60 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// uuidRe regular expression used to validate UUIDs in the format "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"
+var uuidRe = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// ValidateChordSet checks a chord set (e.g. a hand-edited JSON file) against the invariants of generated sets
+// with the default settings: the number of chords, non-empty chord names, notes within the default relative range,
+// no duplicate notes within a chord, a well-formed UUID and a supported schema version.
+// It returns all problems found, or nil if the set is valid.
+func ValidateChordSet(set ChordSet) []error {
+	return validateChordSet(set, defaultMaxChords, defaultMinRelNote, defaultMaxRelNote)
+}
+
+// validateChordSet checks a chord set against the given number of chords and relative note range.
+func validateChordSet(set ChordSet, maxChords, minNote, maxNote int) []error {
+	var errs []error
+
+	if set.Name == "" {
+		errs = append(errs, fmt.Errorf("set name is empty"))
+	}
+
+	if len(set.Chords) != maxChords {
+		errs = append(errs, fmt.Errorf("set has %d chords, expected %d", len(set.Chords), maxChords))
+	}
+
+	for i, chord := range set.Chords {
+		if chord.Name == "" {
+			errs = append(errs, fmt.Errorf("chord %d: name is empty", i+1))
+		}
+
+		seen := make(map[int]bool, len(chord.Notes))
+		for _, note := range chord.Notes {
+			if note < minNote || note > maxNote {
+				errs = append(errs, fmt.Errorf("chord %d: note %d is out of range %d..%d", i+1, note, minNote, maxNote))
+			}
+			if seen[note] {
+				errs = append(errs, fmt.Errorf("chord %d: duplicate note %d", i+1, note))
+			}
+			seen[note] = true
+		}
+	}
+
+	if !uuidRe.MatchString(set.UUID) {
+		errs = append(errs, fmt.Errorf("invalid UUID %q", set.UUID))
+	}
+
+	sch, err := lookupSchema(set.Version)
+	if err != nil {
+		errs = append(errs, err)
+	} else if set.TypeID != sch.typeID {
+		errs = append(errs, fmt.Errorf("type id %q doesn't match schema version %s (expected %q)", set.TypeID, set.Version, sch.typeID))
+	}
+
+	return errs
+}
@@ -0,0 +1,58 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// validSet returns a chord set generated from a fixture folder.
+func validSet(t *testing.T) ChordSet {
+	t.Helper()
+
+	c := testConverter()
+	sets := convertFS(t, &c, fstest.MapFS{"Set/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67))})
+
+	return sets[0]
+}
+
+func TestValidateChordSetValid(t *testing.T) {
+	if errs := ValidateChordSet(validSet(t)); errs != nil {
+		t.Errorf("ValidateChordSet() = %v, want no errors", errs)
+	}
+}
+
+func TestValidateChordSetBroken(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(set *ChordSet)
+		want   []string
+	}{
+		{"missing chords", func(set *ChordSet) { set.Chords = set.Chords[:11] }, []string{"set has 11 chords, expected 12"}},
+		{"empty names", func(set *ChordSet) {
+			set.Name = ""
+			set.Chords[3].Name = ""
+		}, []string{"set name is empty", "chord 4: name is empty"}},
+		{"notes", func(set *ChordSet) { set.Chords[0].Notes = []int{0, 4, 4, 200} }, []string{
+			"chord 1: duplicate note 4", "chord 1: note 200 is out of range",
+		}},
+		{"uuid", func(set *ChordSet) { set.UUID = "not-a-uuid" }, []string{`invalid UUID "not-a-uuid"`}},
+		{"type id", func(set *ChordSet) { set.TypeID = "other" }, []string{`type id "other" doesn't match`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := validSet(t)
+			tt.modify(&set)
+
+			errs := ValidateChordSet(set)
+			if len(errs) != len(tt.want) {
+				t.Fatalf("ValidateChordSet() = %v, want %d errors", errs, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("error %d = %q, want %q", i+1, errs[i], want)
+				}
+			}
+		})
+	}
+}
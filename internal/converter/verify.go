@@ -0,0 +1,102 @@
+package converter
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// VerifyResult is the result of the round-trip check of a chord set.
+type VerifyResult struct {
+	Set  ChordSet     // chord set converted from the MIDI files
+	Diff ChordSetDiff // differences between the chord set and its round-trip conversion
+	Err  error        // error of the round trip, if any
+}
+
+// Passed reports whether the chord set survived the round trip without changes.
+func (r VerifyResult) Passed() bool {
+	return r.Err == nil && r.Diff.Empty()
+}
+
+// Verify checks that the parser and the MIDI exporter agree. The chord sets are converted from the MIDI files
+// without writing any files, then each set is exported to MIDI files (see ExportChordSetToMIDI), converted back
+// and compared with the original set. Chord names and notes are compared, velocities and comments are not.
+func (c *Converter) Verify() ([]VerifyResult, error) {
+	return c.verify((*Converter).Run)
+}
+
+// VerifyZip is like Verify, but converts the chord sets of a zip archive (see RunZip).
+func (c *Converter) VerifyZip(zipPath string) ([]VerifyResult, error) {
+	return c.verify(func(vc *Converter) error { return vc.RunZip(zipPath) })
+}
+
+// verify converts the chord sets with run on a copy of the Converter that writes no files
+// and checks the round trip of each set.
+func (c *Converter) verify(run func(vc *Converter) error) ([]VerifyResult, error) {
+	vc := *c
+	vc.logger = slog.New(slog.DiscardHandler)
+	vc.dryRun = true
+	vc.outputWriter = io.Discard
+	vc.combinedFile = ""
+	vc.cleanOutput = false
+	vc.omitEmptyChords = false
+	vc.dedupeSets = false
+
+	if err := run(&vc); err != nil {
+		return nil, err
+	}
+
+	results := make([]VerifyResult, 0, len(vc.chordSets))
+	for _, set := range vc.chordSets {
+		result := VerifyResult{Set: set}
+
+		roundTrip, err := c.roundTrip(set)
+		if err != nil {
+			result.Err = err
+		} else {
+			result.Diff = DiffChordSets(set, roundTrip)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// roundTrip exports the chord set to MIDI files in a temporary folder and converts them back to a chord set.
+// The files are read with the note settings of the Converter, but without note transformations
+// (transposition, normalization, range and channel filters), since the exported notes are already transformed.
+func (c *Converter) roundTrip(set ChordSet) (ChordSet, error) {
+	tmpDir, err := os.MkdirTemp("", "chords-verify-")
+	if err != nil {
+		return ChordSet{}, fmt.Errorf("error creating temporary folder: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := ExportChordSetToMIDI(set, filepath.Join(tmpDir, "set"), c.noteOffset(c.baseNote)); err != nil {
+		return ChordSet{}, err
+	}
+
+	rc := New()
+	rc.SetLogger(slog.New(slog.DiscardHandler))
+	rc.SetSetsFolder(tmpDir)
+	rc.SetDryRun(true)
+	rc.SetBaseNote(c.baseNote)
+	rc.SetNoteMode(c.noteMode)
+	rc.SetMaxChords(c.maxChords)
+	rc.SetChordNumberBase(c.numberBase)
+	rc.SetEmptyChordName(c.emptyChordName)
+	rc.SetNoteSort(c.noteSort)
+	rc.SetNameSource(NameSourceMetaText) // the exported file names contain sanitized chord names, the track names don't
+
+	if err := rc.Run(); err != nil {
+		return ChordSet{}, err
+	}
+	if len(rc.chordSets) != 1 {
+		return ChordSet{}, fmt.Errorf("round trip of set %s produced %d sets", set.Name, len(rc.chordSets))
+	}
+
+	return rc.chordSets[0], nil
+}
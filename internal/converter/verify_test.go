@@ -0,0 +1,52 @@
+package converter
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestVerifySlashChord(t *testing.T) {
+	root := writeTree(t, fstest.MapFS{
+		"Set/1 Slash.mid": smfFile(smfNamedChord(t, "C/E", 64, 67, 72)),
+		"Set/2 Cmaj.mid":  smfFile(smfChord(t, 60, 64, 67)),
+	})
+
+	c := testConverter()
+	c.SetSetsFolder(root)
+	c.SetNameSource(NameSourceMetaText)
+
+	results, err := c.Verify()
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	if !results[0].Passed() {
+		t.Errorf("set failed verification: err = %v, diff = %s", results[0].Err, results[0].Diff)
+	}
+	if got := results[0].Set.Chords[0].Name; got != "C/E" {
+		t.Errorf("chord 1 name = %q, want %q", got, "C/E")
+	}
+}
+
+func TestVerifyDetectsLossySet(t *testing.T) {
+	root := writeTree(t, fstest.MapFS{
+		"Set/1 Cmaj.mid": smfFile(smfChord(t, 60, 64, 67)),
+	})
+
+	c := testConverter()
+	c.SetSetsFolder(root)
+	c.SetTransform(func(set ChordSet) (ChordSet, error) {
+		set.Chords[0].Notes = append(set.Chords[0].Notes, 200) // out of MIDI range, can't be exported
+		return set, nil
+	})
+
+	results, err := c.Verify()
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Passed() {
+		t.Errorf("results = %+v, want a failed set", results)
+	}
+}
@@ -0,0 +1,61 @@
+package converter
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+// walkDirSorted is like fs.WalkDir, but explicitly sorts the entries of each directory by name before visiting them.
+// fs.WalkDir relies on the file system to return sorted entries, which not every fs.FS implementation does;
+// the order of files determines the output (e.g. which file is reported as a duplicate), so it must not depend
+// on the platform or the file system.
+func walkDirSorted(fsys fs.FS, root string, fn fs.WalkDirFunc) error {
+	info, err := fs.Stat(fsys, root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkDir(fsys, root, fs.FileInfoToDirEntry(info), fn)
+	}
+	if errors.Is(err, fs.SkipDir) || errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+
+	return err
+}
+
+// walkDir recursively visits the directory entry d at name and, if it is a directory, its sorted entries.
+func walkDir(fsys fs.FS, name string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(name, d, nil); err != nil || !d.IsDir() {
+		if errors.Is(err, fs.SkipDir) && d.IsDir() {
+			err = nil // the directory is skipped, but not its siblings
+		}
+		return err
+	}
+
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		// report the error of reading the directory, the entries read before it are still visited.
+		if err = fn(name, d, err); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				err = nil
+			}
+			return err
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+
+	for _, entry := range entries {
+		if err := walkDir(fsys, path.Join(name, entry.Name()), entry, fn); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				break // a file asked to skip the rest of its directory
+			}
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package converter
+
+import (
+	"io/fs"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+// shuffledFS is a file system that returns the entries of directories in random order.
+type shuffledFS struct {
+	fstest.MapFS
+	rand *rand.Rand
+}
+
+func (s shuffledFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := s.MapFS.ReadDir(name)
+	s.rand.Shuffle(len(entries), func(i, j int) { entries[i], entries[j] = entries[j], entries[i] })
+
+	return entries, err
+}
+
+func TestWalkDirSorted(t *testing.T) {
+	fsys := shuffledFS{fstest.MapFS{
+		"b/2.mid":   {},
+		"b/1.mid":   {},
+		"a/x/3.mid": {},
+		"c.mid":     {},
+	}, rand.New(rand.NewPCG(1, 2))}
+
+	var visited []string
+	err := walkDirSorted(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		visited = append(visited, path)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("walkDirSorted() error = %v", err)
+	}
+
+	want := []string{".", "a", "a/x", "a/x/3.mid", "b", "b/1.mid", "b/2.mid", "c.mid"}
+	if !slices.Equal(visited, want) {
+		t.Errorf("visited %q, want %q", visited, want)
+	}
+}
+
+func TestConvertFolderShuffledEntries(t *testing.T) {
+	fsys := fixtureTree(t, 4, 12)
+	// duplicate numbers: the first file in name order wins
+	fsys["Set 02/1 Another.mid"] = smfFile(smfChord(t, 60, 63, 67))
+
+	c := testConverter()
+	c.SetContinueOnError(true)
+	want, _ := c.ConvertFolder(fsys, ".")
+	if len(want) != 4 {
+		t.Fatalf("got %d sets, want 4", len(want))
+	}
+
+	for seed := range uint64(10) {
+		c := testConverter()
+		c.SetContinueOnError(true)
+		got, _ := c.ConvertFolder(shuffledFS{fsys, rand.New(rand.NewPCG(seed, seed))}, ".")
+		if !slices.Equal(setNames(got), setNames(want)) {
+			t.Fatalf("seed %d: sets = %q, want %q", seed, setNames(got), setNames(want))
+		}
+		for i := range got {
+			if !reflect.DeepEqual(got[i].Chords, want[i].Chords) {
+				t.Errorf("seed %d: chords of %s = %+v, want %+v", seed, got[i].Name, got[i].Chords, want[i].Chords)
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const watchDebounce = 500 * time.Millisecond // delay after the last file change before the sets are regenerated
+
+// Watch runs the conversion and then re-runs it whenever MIDI files or folders under the sets folder (or any of the input folders) change,
+// until the context is cancelled. Rapid changes (e.g. copying many files) are debounced into a single run.
+// Errors of the individual runs are logged and don't stop watching.
+func (c *Converter) Watch(ctx context.Context) error {
+	if err := c.getSetsFolder(); err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("error creating file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	for _, folder := range c.inputFolders() {
+		if err := c.watchTree(watcher, folder); err != nil {
+			return err
+		}
+	}
+
+	c.runWatched(ctx)
+	c.logger.Info("watching for changes", "folder", strings.Join(c.inputFolders(), ", "))
+
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !c.isWatchedChange(event) {
+				continue
+			}
+			// watch folders created after the start (e.g. a new chord set).
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := c.watchTree(watcher, event.Name); err != nil {
+						c.logger.Error("can't watch folder", "folder", event.Name, "error", err)
+					}
+				}
+			}
+			timer.Reset(watchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			c.logger.Error("file watcher error", "error", err)
+		case <-timer.C:
+			c.runWatched(ctx)
+		}
+	}
+}
+
+// runWatched runs the conversion and logs its result. A run interrupted by the cancellation of the context
+// is not reported.
+func (c *Converter) runWatched(ctx context.Context) {
+	if err := c.RunContext(ctx); err != nil {
+		if ctx.Err() == nil {
+			c.logger.Error("conversion failed", "error", err)
+		}
+		return
+	}
+
+	c.logger.Info(c.LastSummary().String())
+}
+
+// watchTree adds the folder and all its subfolders to the watcher.
+func (c *Converter) watchTree(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("error watching folder %s: %w", path, err)
+		}
+
+		return nil
+	})
+}
+
+// isWatchedChange reports whether the event affects the chord sets: a change of a MIDI file, a set config file
+// (set.json) or a folder. Changes of other files (including the generated JSON files) are ignored.
+func (c *Converter) isWatchedChange(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+
+	if c.isMidiFile(event.Name) || filepath.Base(event.Name) == setConfigFileName {
+		return true
+	}
+
+	info, err := os.Stat(event.Name)
+	if err != nil {
+		// the path no longer exists: a removed or renamed folder has no file extension as a rule.
+		return filepath.Ext(event.Name) == ""
+	}
+
+	return info.IsDir()
+}
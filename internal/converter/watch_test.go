@@ -0,0 +1,44 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestIsWatchedChange(t *testing.T) {
+	root := t.TempDir()
+	setDir := filepath.Join(root, "Set")
+	if err := os.Mkdir(setDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"notes.txt", "user_chord_set_01.json", "set.json"} {
+		if err := os.WriteFile(filepath.Join(root, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := testConverter()
+	tests := []struct {
+		name string
+		op   fsnotify.Op
+		want bool
+	}{
+		{filepath.Join(setDir, "1 Cmaj.mid"), fsnotify.Write, true},
+		{filepath.Join(setDir, "1 Cmaj.MIDI"), fsnotify.Create, true},
+		{filepath.Join(setDir, "set.json"), fsnotify.Write, true},
+		{filepath.Join(setDir, "set.json"), fsnotify.Remove, true},
+		{setDir, fsnotify.Create, true},
+		{filepath.Join(root, "Removed"), fsnotify.Remove, true},
+		{filepath.Join(root, "notes.txt"), fsnotify.Write, false},
+		{filepath.Join(root, "user_chord_set_01.json"), fsnotify.Write, false},
+		{filepath.Join(setDir, "1 Cmaj.mid"), fsnotify.Chmod, false},
+	}
+	for _, tt := range tests {
+		if got := c.isWatchedChange(fsnotify.Event{Name: tt.name, Op: tt.op}); got != tt.want {
+			t.Errorf("isWatchedChange(%s %s) = %v, want %v", tt.op, tt.name, got, tt.want)
+		}
+	}
+}
@@ -2,16 +2,152 @@ package helpers
 
 import (
 	"crypto/rand"
+	"crypto/sha1"
 	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
 )
 
 // GenerateUUID generates a random UUID.
-func GenerateUUID() string {
+func GenerateUUID() (string, error) {
+	return generateUUID(rand.Reader)
+}
+
+// generateUUID generates a UUID from 16 bytes read from r.
+func generateUUID(r io.Reader) (string, error) {
 	b := make([]byte, 16)
-	_, err := rand.Read(b)
-	if err != nil {
-		return "00000000-0000-0000-0000-000000000000"
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", fmt.Errorf("error generating UUID: %w", err)
 	}
 
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// GenerateNameUUID generates a name-based UUID (version 5): the same namespace and name always give the same UUID.
+func GenerateNameUUID(namespace [16]byte, name []byte) string {
+	h := sha1.New()
+	h.Write(namespace[:])
+	h.Write(name)
+	b := h.Sum(nil)[:16]
+
+	b[6] = (b[6] & 0x0f) | 0x50 // version 5
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+
 	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
 }
+
+// StripControlChars removes control characters (e.g. newlines, tabs, escape codes) from a string.
+func StripControlChars(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// NormalizeName cleans up a name for display: it removes control characters, trims leading and trailing
+// whitespace and collapses inner runs of whitespace into single spaces, e.g. " Cmin   7 " becomes "Cmin 7".
+func NormalizeName(name string) string {
+	return strings.Join(strings.Fields(StripControlChars(name)), " ")
+}
+
+// SanitizeName makes a name safe for use as a file name on all platforms.
+// It removes control characters, replaces path separators and characters reserved on Windows (<>:"/\|?*)
+// with underscores, and trims leading and trailing spaces and trailing dots. Unicode letters are kept as is.
+func SanitizeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, StripControlChars(name))
+
+	return strings.TrimRight(strings.TrimSpace(name), ". ")
+}
+
+// NoteNaming defines how note names are spelled. The zero value spells black keys with sharps
+// and labels middle C (MIDI note 60) as C3, as Maschine does.
+type NoteNaming struct {
+	Flats    bool // if true, black keys are spelled with flats (Db) instead of sharps (C#)
+	MiddleC4 bool // if true, middle C is labeled C4 (scientific pitch notation) instead of C3
+}
+
+// sharpNames and flatNames contain the names of the pitch classes, starting from C.
+var (
+	sharpNames = [12]string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+	flatNames  = [12]string{"C", "Db", "D", "Eb", "E", "F", "Gb", "G", "Ab", "A", "Bb", "B"}
+)
+
+// PitchClassName returns the name of the pitch class of a MIDI note number without the octave, e.g. "C#" or "Db".
+func PitchClassName(midi int, naming NoteNaming) string {
+	pc := (midi%12 + 12) % 12
+	if naming.Flats {
+		return flatNames[pc]
+	}
+
+	return sharpNames[pc]
+}
+
+// NoteName returns the name of a MIDI note number with the octave, e.g. 61 is "C#3", or "Db4" with flats
+// and middle C labeled C4. The lowest note 0 is in octave -2 (or -1 with middle C labeled C4).
+func NoteName(midi int, naming NoteNaming) string {
+	octave := midi/12 - 2
+	if midi < 0 && midi%12 != 0 {
+		octave-- // round down for negative values
+	}
+	if naming.MiddleC4 {
+		octave++
+	}
+
+	return PitchClassName(midi, naming) + strconv.Itoa(octave)
+}
+
+// OctaveSpan returns the number of whole octaves by which the chord extends beyond a single octave:
+// 0 for chords within an octave above the lowest note (e.g. 0, 4, 7, 11), 1 for chords up to two octaves
+// (e.g. 0, 7, 16), and so on.
+func OctaveSpan(notes []int) int {
+	if len(notes) == 0 {
+		return 0
+	}
+
+	return (slices.Max(notes) - slices.Min(notes)) / 12
+}
+
+// FoldNote moves the note by whole octaves into the range lo..hi, e.g. 30 becomes 18 in the range -24..24.
+// Notes within the range are returned unchanged. The range must span at least an octave (hi-lo >= 11),
+// otherwise the result may still be outside of it.
+func FoldNote(n, lo, hi int) int {
+	for n > hi {
+		n -= 12
+	}
+	for n < lo {
+		n += 12
+	}
+
+	return n
+}
+
+// NormalizeChord returns the chord in root position within one octave: every note is moved by whole octaves
+// into the octave starting at the lowest note, notes that collide are kept once, and the result is sorted
+// in ascending order. Negative (relative) note values are supported.
+func NormalizeChord(notes []int) []int {
+	if len(notes) == 0 {
+		return nil
+	}
+
+	lowest := slices.Min(notes)
+	result := make([]int, 0, len(notes))
+	for _, n := range notes {
+		folded := lowest + ((n-lowest)%12+12)%12
+		if !slices.Contains(result, folded) {
+			result = append(result, folded)
+		}
+	}
+	slices.Sort(result)
+
+	return result
+}
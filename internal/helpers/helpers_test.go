@@ -0,0 +1,200 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+// failingReader is an io.Reader that always fails.
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("entropy source unavailable")
+}
+
+var uuidRe = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+func TestGenerateUUID(t *testing.T) {
+	a, err := GenerateUUID()
+	if err != nil {
+		t.Fatalf("GenerateUUID() error = %v", err)
+	}
+	b, err := GenerateUUID()
+	if err != nil {
+		t.Fatalf("GenerateUUID() error = %v", err)
+	}
+
+	if !uuidRe.MatchString(a) {
+		t.Errorf("GenerateUUID() = %q, not a UUID", a)
+	}
+	if a == b {
+		t.Errorf("GenerateUUID() returned %q twice", a)
+	}
+}
+
+func TestGenerateUUIDFromReader(t *testing.T) {
+	got, err := generateUUID(bytes.NewReader(bytes.Repeat([]byte{0xab}, 16)))
+	if err != nil {
+		t.Fatalf("generateUUID() error = %v", err)
+	}
+	if want := "abababab-abab-abab-abab-abababababab"; got != want {
+		t.Errorf("generateUUID() = %q, want %q", got, want)
+	}
+}
+
+func TestGenerateUUIDFailingReader(t *testing.T) {
+	for name, r := range map[string]io.Reader{
+		"failing": failingReader{},
+		"short":   bytes.NewReader(make([]byte, 8)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := generateUUID(r)
+			if err == nil {
+				t.Fatalf("generateUUID() = %q, want an error", got)
+			}
+			if got != "" {
+				t.Errorf("generateUUID() = %q, want an empty string on error", got)
+			}
+		})
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Jazz Chords", "Jazz Chords"},
+		{"  Pads  ", "Pads"},
+		{"Dúo Ñandú – 七", "Dúo Ñandú – 七"},
+		{`a<b>c:d"e/f\g|h?i*j`, "a_b_c_d_e_f_g_h_i_j"},
+		{"../../etc", ".._.._etc"},
+		{"Tab\tBell\a", "TabBell"},
+		{"Ends with dots...", "Ends with dots"},
+		{" . ", ""},
+	}
+	for _, tt := range tests {
+		if got := SanitizeName(tt.name); got != tt.want {
+			t.Errorf("SanitizeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeChord(t *testing.T) {
+	tests := []struct {
+		name  string
+		notes []int
+		want  []int
+	}{
+		{"empty", nil, nil},
+		{"root position", []int{0, 4, 7}, []int{0, 4, 7}},
+		{"first inversion", []int{4, 7, 12}, []int{4, 7, 12}},
+		{"wide spread", []int{0, 16, 31}, []int{0, 4, 7}},
+		{"unsorted", []int{19, 0, 28}, []int{0, 4, 7}},
+		{"unison collisions", []int{0, 12, 24, 7, 19}, []int{0, 7}},
+		{"doubled root", []int{0, 0, 4}, []int{0, 4}},
+		{"negative notes", []int{-12, -5, 4}, []int{-12, -8, -5}},
+	}
+	for _, tt := range tests {
+		if got := NormalizeChord(tt.notes); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: NormalizeChord(%v) = %v, want %v", tt.name, tt.notes, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Cmin", "Cmin"},
+		{"Cmin ", "Cmin"},
+		{"  Cmin   7 ", "Cmin 7"},
+		{"Jazz \u00a0Chords   II", "Jazz Chords II"},
+		{"Pads\x00 Warm", "Pads Warm"},
+		{"   ", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeName(tt.name); got != tt.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNoteNamePitchClasses(t *testing.T) {
+	sharps := []string{"C3", "C#3", "D3", "D#3", "E3", "F3", "F#3", "G3", "G#3", "A3", "A#3", "B3"}
+	flats := []string{"C4", "Db4", "D4", "Eb4", "E4", "F4", "Gb4", "G4", "Ab4", "A4", "Bb4", "B4"}
+
+	for i := range 12 {
+		if got := NoteName(60+i, NoteNaming{}); got != sharps[i] {
+			t.Errorf("NoteName(%d, sharps) = %q, want %q", 60+i, got, sharps[i])
+		}
+		if got := NoteName(60+i, NoteNaming{Flats: true, MiddleC4: true}); got != flats[i] {
+			t.Errorf("NoteName(%d, flats, C4) = %q, want %q", 60+i, got, flats[i])
+		}
+	}
+}
+
+func TestNoteNameOctaves(t *testing.T) {
+	tests := []struct {
+		midi   int
+		naming NoteNaming
+		want   string
+	}{
+		{0, NoteNaming{}, "C-2"},
+		{0, NoteNaming{MiddleC4: true}, "C-1"},
+		{127, NoteNaming{}, "G8"},
+		{-1, NoteNaming{}, "B-3"},
+		{-13, NoteNaming{Flats: true}, "B-4"},
+	}
+	for _, tt := range tests {
+		if got := NoteName(tt.midi, tt.naming); got != tt.want {
+			t.Errorf("NoteName(%d, %+v) = %q, want %q", tt.midi, tt.naming, got, tt.want)
+		}
+	}
+}
+
+func TestFoldNote(t *testing.T) {
+	tests := []struct {
+		n, lo, hi int
+		want      int
+	}{
+		{0, -24, 24, 0},
+		{24, -24, 24, 24},
+		{30, -24, 24, 18},
+		{60, -24, 24, 24},
+		{-25, -24, 24, -13},
+		{-61, -24, 24, -13},
+		{13, 0, 11, 1},
+		{-1, 0, 11, 11},
+	}
+	for _, tt := range tests {
+		if got := FoldNote(tt.n, tt.lo, tt.hi); got != tt.want {
+			t.Errorf("FoldNote(%d, %d, %d) = %d, want %d", tt.n, tt.lo, tt.hi, got, tt.want)
+		}
+	}
+}
+
+func TestOctaveSpan(t *testing.T) {
+	tests := []struct {
+		notes []int
+		want  int
+	}{
+		{nil, 0},
+		{[]int{0}, 0},
+		{[]int{0, 4, 7, 11}, 0},
+		{[]int{0, 12}, 1},
+		{[]int{0, 7, 16}, 1},
+		{[]int{-12, 4, 19}, 2},
+		{[]int{24, 0, 36}, 3},
+	}
+	for _, tt := range tests {
+		if got := OctaveSpan(tt.notes); got != tt.want {
+			t.Errorf("OctaveSpan(%v) = %d, want %d", tt.notes, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// ANSI escape sequences used for colored output.
+const (
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorReset  = "\x1b[0m"
+)
+
+// successPrefix is the message prefix of info records that are highlighted as successful (e.g. "generated file").
+const successPrefix = "generated"
+
+// Handler is a slog.Handler that writes human-readable log lines for the console:
+// the message followed by key=value attributes, prefixed by the level for warnings and errors.
+type Handler struct {
+	mu     *sync.Mutex  // guards writes to w, shared between derived handlers
+	w      io.Writer    // destination of log lines
+	level  slog.Leveler // minimum level of logged records
+	color  bool         // if true, lines are colored with ANSI escape sequences
+	attrs  []slog.Attr  // attributes added by WithAttrs
+	groups []string     // group names added by WithGroup
+}
+
+// NewHandler creates a Handler that writes records of the given level and above to w.
+func NewHandler(w io.Writer, level slog.Leveler) *Handler {
+	return &Handler{mu: &sync.Mutex{}, w: w, level: level}
+}
+
+// SetColor enables coloring of log lines: errors are red, warnings are yellow and successful results
+// (e.g. generated files) are green. It must be called before the handler is used.
+func (h *Handler) SetColor(enabled bool) {
+	h.color = enabled
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle writes the record as a single line.
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+
+	color := h.lineColor(r)
+	buf.WriteString(color)
+
+	switch {
+	case r.Level >= slog.LevelError:
+		buf.WriteString("error: ")
+	case r.Level >= slog.LevelWarn:
+		buf.WriteString("warning: ")
+	case r.Level < slog.LevelInfo:
+		buf.WriteString("debug: ")
+	}
+	buf.WriteString(r.Message)
+
+	for _, a := range h.attrs {
+		writeAttr(&buf, a)
+	}
+	prefix := strings.Join(h.groups, ".")
+	r.Attrs(func(a slog.Attr) bool {
+		if prefix != "" {
+			a.Key = prefix + "." + a.Key
+		}
+		writeAttr(&buf, a)
+		return true
+	})
+	if color != "" {
+		buf.WriteString(colorReset)
+	}
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf.Bytes())
+
+	return err
+}
+
+// lineColor returns the escape sequence for the color of the record, or an empty string if it is not colored.
+func (h *Handler) lineColor(r slog.Record) string {
+	if !h.color {
+		return ""
+	}
+
+	switch {
+	case r.Level >= slog.LevelError:
+		return colorRed
+	case r.Level >= slog.LevelWarn:
+		return colorYellow
+	case strings.HasPrefix(r.Message, successPrefix):
+		return colorGreen
+	}
+
+	return ""
+}
+
+// WithAttrs returns a handler that adds the given attributes to every record.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	prefix := strings.Join(h.groups, ".")
+	h2.attrs = append([]slog.Attr{}, h.attrs...)
+	for _, a := range attrs {
+		if prefix != "" {
+			a.Key = prefix + "." + a.Key
+		}
+		h2.attrs = append(h2.attrs, a)
+	}
+
+	return &h2
+}
+
+// WithGroup returns a handler that qualifies the keys of subsequent attributes with the group name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(append([]string{}, h.groups...), name)
+
+	return &h2
+}
+
+// writeAttr writes the attribute as " key=value", quoting values that contain spaces.
+func writeAttr(buf *bytes.Buffer, a slog.Attr) {
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	value := a.Value.Resolve().String()
+	if value == "" || strings.ContainsAny(value, " \t\"=") {
+		value = fmt.Sprintf("%q", value)
+	}
+	fmt.Fprintf(buf, " %s=%s", a.Key, value)
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// logAll logs a record of each kind that is colored when colors are enabled.
+func logAll(color bool) string {
+	var buf strings.Builder
+	h := NewHandler(&buf, slog.LevelInfo)
+	h.SetColor(color)
+
+	log := slog.New(h)
+	log.Info("generated file", "path", "out/user_chord_set_01.json")
+	log.Warn("no notes found in file", "file", "Set/1 C.mid")
+	log.Error("file skipped", "error", "invalid file name")
+
+	return buf.String()
+}
+
+func TestHandlerWithoutColor(t *testing.T) {
+	got := logAll(false)
+
+	if strings.Contains(got, "\x1b[") {
+		t.Errorf("output contains ANSI escape sequences:\n%q", got)
+	}
+	want := "generated file path=out/user_chord_set_01.json\n" +
+		"warning: no notes found in file file=\"Set/1 C.mid\"\n" +
+		"error: file skipped error=\"invalid file name\"\n"
+	if got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHandlerWithColor(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(logAll(true), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3: %q", len(lines), lines)
+	}
+
+	for i, color := range []string{colorGreen, colorYellow, colorRed} {
+		if !strings.HasPrefix(lines[i], color) || !strings.HasSuffix(lines[i], colorReset) {
+			t.Errorf("line %d = %q, want it colored with %q", i+1, lines[i], color)
+		}
+	}
+}